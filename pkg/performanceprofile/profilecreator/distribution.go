@@ -0,0 +1,162 @@
+package profilecreator
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/jaypipes/ghw/pkg/cpu"
+	"github.com/jaypipes/ghw/pkg/topology"
+)
+
+// DistributionPolicy controls how getReservedCPUs orders the cores it draws
+// from, on top of the NUMA-cell iteration it already does. getOfflinedCPUs
+// consults it only for DistributionBalancedNUMA.
+type DistributionPolicy string
+
+const (
+	// DistributionSequential is the historical behavior: cores are consumed
+	// in topology order, NUMA node by NUMA node.
+	DistributionSequential DistributionPolicy = "sequential"
+	// DistributionBalancedNUMA spreads the selection as evenly as possible
+	// across NUMA nodes. Equivalent to splitReservedCPUsAcrossNUMA/
+	// splitOfflinedCPUsAcrossNUMA, offered here as an explicit policy name.
+	DistributionBalancedNUMA DistributionPolicy = "balanced-numa"
+	// DistributionSpreadL3Cache groups cores by shared last-level cache
+	// (L3) domain and fills one L3 domain before spilling into the next, so
+	// a reservation never straddles two L3 domains unless it has to.
+	DistributionSpreadL3Cache DistributionPolicy = "spread-l3-cache"
+)
+
+// sysCPUSharedL3PathFmt is templated with a logical processor id to read the
+// set of logical processors sharing its last-level cache. It is relative to
+// a sysfsRoot rather than an absolute host path, since the profile creator
+// runs against a must-gather/GHW snapshot rather than live hardware; see
+// llcGroupOf.
+const sysCPUSharedL3PathFmt = "sys/devices/system/cpu/cpu%d/cache/index3/shared_cpu_list"
+
+// llcGroupOf reads the L3 shared_cpu_list for lpID, rooted at sysfsRoot (the
+// extracted must-gather/GHW snapshot directory the rest of this package
+// reads sysfs-shaped files from, or "/" when running directly against live
+// hardware), and returns the lowest logical processor id in that list as a
+// stable group identifier. It returns lpID itself (i.e. a singleton group)
+// when the attribute is unavailable, so callers degenerate to sequential
+// order on snapshots that don't capture it. It is a package variable so
+// tests can substitute a synthetic topology instead of reading sysfs.
+var llcGroupOf = func(sysfsRoot string, lpID int) int {
+	f, err := os.Open(path.Join(sysfsRoot, fmt.Sprintf(sysCPUSharedL3PathFmt, lpID)))
+	if err != nil {
+		return lpID
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() {
+		return lpID
+	}
+	members, err := parseCPUList(scanner.Text())
+	if err != nil || len(members) == 0 {
+		return lpID
+	}
+
+	group := members[0]
+	for _, m := range members[1:] {
+		if m < group {
+			group = m
+		}
+	}
+	return group
+}
+
+// parseCPUList parses a Linux-style CPU list such as "0-3,8,10-11" into a
+// slice of logical processor ids, in the order the list gives them; the
+// result is not sorted.
+func parseCPUList(s string) ([]int, error) {
+	var ids []int
+	for _, field := range strings.Split(strings.TrimSpace(s), ",") {
+		if field == "" {
+			continue
+		}
+		bounds := strings.SplitN(field, "-", 2)
+		start, err := strconv.Atoi(strings.TrimSpace(bounds[0]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid cpu list %q: %w", s, err)
+		}
+		end := start
+		if len(bounds) == 2 {
+			end, err = strconv.Atoi(strings.TrimSpace(bounds[1]))
+			if err != nil {
+				return nil, fmt.Errorf("invalid cpu list %q: %w", s, err)
+			}
+		}
+		for id := start; id <= end; id++ {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// reorderCoresForL3Packing returns topologyInfoNodes with each node's Cores
+// slice reordered so cores sharing an L3 domain are contiguous, domains
+// ordered by first appearance. This lets the existing cpuAccumulator-based
+// selection in getReservedCPUs fill one L3 domain before spilling into the
+// next, without requiring the accumulator itself to become LLC-aware.
+// sysfsRoot is the root llcGroupOf resolves shared_cpu_list from. If no
+// L3-sharing is detected anywhere in topologyInfoNodes (the attribute isn't
+// present under sysfsRoot, e.g. an older must-gather snapshot), this Alerts
+// instead of silently falling back to sequential order. The input is not
+// mutated.
+func reorderCoresForL3Packing(topologyInfoNodes []*topology.Node, sysfsRoot string) []*topology.Node {
+	if !anyLLCGroupingDetected(topologyInfoNodes, sysfsRoot) {
+		Alert("distribution policy %q could not be honored: no L3 cache-sharing data found under %q; falling back to sequential order", DistributionSpreadL3Cache, sysfsRoot)
+	}
+
+	out := make([]*topology.Node, 0, len(topologyInfoNodes))
+	for _, node := range topologyInfoNodes {
+		groupOrder := []int{}
+		groups := map[int][]*cpu.ProcessorCore{}
+		for _, core := range node.Cores {
+			group := lpID(core, sysfsRoot)
+			if _, ok := groups[group]; !ok {
+				groupOrder = append(groupOrder, group)
+			}
+			groups[group] = append(groups[group], core)
+		}
+
+		cores := make([]*cpu.ProcessorCore, 0, len(node.Cores))
+		for _, group := range groupOrder {
+			cores = append(cores, groups[group]...)
+		}
+		out = append(out, &topology.Node{ID: node.ID, Cores: cores})
+	}
+	return out
+}
+
+// lpID returns the L3 group id representative for a core, based on its first
+// logical processor.
+func lpID(core *cpu.ProcessorCore, sysfsRoot string) int {
+	if len(core.LogicalProcessors) == 0 {
+		return -1
+	}
+	return llcGroupOf(sysfsRoot, core.LogicalProcessors[0])
+}
+
+// anyLLCGroupingDetected reports whether llcGroupOf found real L3-sharing
+// data (a group other than a core's own logical processor id) for at least
+// one core in topologyInfoNodes.
+func anyLLCGroupingDetected(topologyInfoNodes []*topology.Node, sysfsRoot string) bool {
+	for _, node := range topologyInfoNodes {
+		for _, core := range node.Cores {
+			if len(core.LogicalProcessors) == 0 {
+				continue
+			}
+			if llcGroupOf(sysfsRoot, core.LogicalProcessors[0]) != core.LogicalProcessors[0] {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package profilecreator
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jaypipes/ghw/pkg/cpu"
+	"github.com/jaypipes/ghw/pkg/topology"
+)
+
+func TestParseCPUList(t *testing.T) {
+	cases := map[string]struct {
+		in      string
+		want    []int
+		wantErr bool
+	}{
+		"single range":     {in: "0-3", want: []int{0, 1, 2, 3}},
+		"mixed":            {in: "0-1,4,6-7", want: []int{0, 1, 4, 6, 7}},
+		"single cpu":       {in: "5", want: []int{5}},
+		"trailing newline": {in: "0-1\n", want: []int{0, 1}},
+		"invalid":          {in: "a-b", wantErr: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := parseCPUList(tc.in)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error for input %q", tc.in)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("parseCPUList(%q) = %v, want %v", tc.in, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestReorderCoresForL3Packing(t *testing.T) {
+	// Two L3 domains of two cores each, interleaved in topology order:
+	// cores 0 and 2 share LLC group 0, cores 1 and 3 share LLC group 10.
+	groups := map[int]int{0: 0, 1: 10, 2: 0, 3: 10, 4: 0, 5: 10, 6: 0, 7: 10}
+	orig := llcGroupOf
+	llcGroupOf = func(_ string, lpID int) int { return groups[lpID] }
+	defer func() { llcGroupOf = orig }()
+
+	nodes := []*topology.Node{
+		{
+			ID: 0,
+			Cores: []*cpu.ProcessorCore{
+				{ID: 0, NumThreads: 1, LogicalProcessors: []int{0}},
+				{ID: 1, NumThreads: 1, LogicalProcessors: []int{1}},
+				{ID: 2, NumThreads: 1, LogicalProcessors: []int{2}},
+				{ID: 3, NumThreads: 1, LogicalProcessors: []int{3}},
+			},
+		},
+	}
+
+	packed := reorderCoresForL3Packing(nodes, "/")
+	if len(packed) != 1 {
+		t.Fatalf("expected 1 NUMA node, got %d", len(packed))
+	}
+
+	got := coreIDs(packed[0].Cores)
+	want := []int{0, 2, 1, 3}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected cores grouped by L3 domain %v, got %v", want, got)
+	}
+
+	if got := coreIDs(nodes[0].Cores); !reflect.DeepEqual(got, []int{0, 1, 2, 3}) {
+		t.Fatalf("expected input node's Cores slice to be left untouched, got %v", got)
+	}
+}
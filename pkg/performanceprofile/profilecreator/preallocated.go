@@ -0,0 +1,80 @@
+package profilecreator
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/utils/cpuset"
+)
+
+// PreallocatedCPUs describes CPUs already committed to other workloads on a
+// node - e.g. from a previously generated profile, a DPDK pod's exclusive
+// CPUs, or an SR-IOV VF pinning list - so a profile can be regenerated
+// against a live cluster without reserving or isolating over CPUs a running
+// latency-critical pod already depends on.
+type PreallocatedCPUs struct {
+	// Reservations lists the individual allocations making up the snapshot.
+	// Keeping them separate, rather than a single flat CPU list, lets
+	// conflict diagnostics name which workload a clashing CPU belongs to.
+	Reservations []PreallocatedReservation `json:"reservations"`
+}
+
+// PreallocatedReservation is a single named allocation within a
+// PreallocatedCPUs snapshot.
+type PreallocatedReservation struct {
+	// Source identifies the workload the CPUs are committed to, e.g. a pod
+	// name or "profile:worker-cnf", surfaced in conflict diagnostics.
+	Source string `json:"source"`
+	// CPUs is a Linux-style CPU list, e.g. "4-7,12".
+	CPUs string `json:"cpus"`
+}
+
+// LoadPreallocatedCPUs reads and parses a PreallocatedCPUs snapshot (JSON or
+// YAML) from path, returning the union of its reservations as a CPUSet for
+// CalculateCPUSets. path is expected to come from a --preallocated-cpus-style
+// CLI flag, but that flag is not wired up in this checkout; see the note on
+// CalculateCPUSets.
+func LoadPreallocatedCPUs(path string) (cpuset.CPUSet, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return cpuset.CPUSet{}, fmt.Errorf("failed to open preallocated CPUs file %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var snapshot PreallocatedCPUs
+	dec := k8syaml.NewYAMLOrJSONDecoder(f, 4096)
+	if err := dec.Decode(&snapshot); err != nil {
+		return cpuset.CPUSet{}, fmt.Errorf("failed to decode preallocated CPUs file %s: %w", path, err)
+	}
+
+	var ids []int
+	for _, r := range snapshot.Reservations {
+		parsed, err := parseCPUList(r.CPUs)
+		if err != nil {
+			return cpuset.CPUSet{}, fmt.Errorf("preallocated CPUs file %s: reservation %q: %w", path, r.Source, err)
+		}
+		ids = append(ids, parsed...)
+	}
+	return cpuset.New(ids...), nil
+}
+
+// newPreallocatedConflictError reports reserved CPUs that collide with an
+// existing PreallocatedCPUs snapshot, naming the conflicting cores/sockets so
+// operators can see exactly what to adjust: a larger reservedCPUCount, a
+// different splitReservedCPUsAcrossNUMA/DistributionPolicy, or freeing the
+// preallocated CPUs.
+func newPreallocatedConflictError(extCpuInfo *extendedCPUInfo, conflict cpuset.CPUSet) error {
+	var conflicts []string
+	for _, processor := range extCpuInfo.CpuInfo.Processors {
+		for _, core := range processor.Cores {
+			for _, lp := range core.LogicalProcessors {
+				if conflict.Contains(lp) {
+					conflicts = append(conflicts, fmt.Sprintf("cpu %d (socket %d, core %d)", lp, processor.ID, core.ID))
+				}
+			}
+		}
+	}
+	return fmt.Errorf("the requested reservation cannot be satisfied without reusing preallocated CPUs: %s", strings.Join(conflicts, ", "))
+}
@@ -0,0 +1,95 @@
+package profilecreator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/utils/cpuset"
+)
+
+func writePreallocatedFile(t *testing.T, name, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+	return path
+}
+
+func TestLoadPreallocatedCPUsUnionsReservationsFromYAML(t *testing.T) {
+	path := writePreallocatedFile(t, "preallocated.yaml", `
+reservations:
+  - source: dpdk-pod
+    cpus: "4-5"
+  - source: profile:worker-cnf
+    cpus: "8,10-11"
+`)
+
+	got, err := LoadPreallocatedCPUs(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := cpuset.New(4, 5, 8, 10, 11)
+	if !got.Equals(want) {
+		t.Fatalf("LoadPreallocatedCPUs() = %s, want %s", got, want)
+	}
+}
+
+func TestLoadPreallocatedCPUsUnionsReservationsFromJSON(t *testing.T) {
+	path := writePreallocatedFile(t, "preallocated.json", `{
+		"reservations": [
+			{"source": "dpdk-pod", "cpus": "4-5"},
+			{"source": "profile:worker-cnf", "cpus": "8,10-11"}
+		]
+	}`)
+
+	got, err := LoadPreallocatedCPUs(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := cpuset.New(4, 5, 8, 10, 11)
+	if !got.Equals(want) {
+		t.Fatalf("LoadPreallocatedCPUs() = %s, want %s", got, want)
+	}
+}
+
+func TestLoadPreallocatedCPUsRejectsMalformedCPUList(t *testing.T) {
+	path := writePreallocatedFile(t, "preallocated.yaml", `
+reservations:
+  - source: dpdk-pod
+    cpus: "a-b"
+`)
+
+	_, err := LoadPreallocatedCPUs(path)
+	if err == nil {
+		t.Fatalf("expected an error for a malformed cpus list")
+	}
+	if !strings.Contains(err.Error(), "dpdk-pod") {
+		t.Fatalf("expected the error to name the offending reservation's source, got: %v", err)
+	}
+}
+
+func TestLoadPreallocatedCPUsRejectsMissingFile(t *testing.T) {
+	_, err := LoadPreallocatedCPUs(filepath.Join(t.TempDir(), "does-not-exist.yaml"))
+	if err == nil {
+		t.Fatalf("expected an error for a missing file")
+	}
+}
+
+func TestNewPreallocatedConflictErrorNamesConflictingCoresAndSockets(t *testing.T) {
+	nodes := fourCoreTwoNUMANodes()
+	extCPUInfo, _ := cpuInfoFromNodes(nodes)
+
+	err := newPreallocatedConflictError(extCPUInfo, cpuset.New(2, 4))
+
+	for _, want := range []string{
+		"cpu 2 (socket 0, core 1)",
+		"cpu 4 (socket 1, core 2)",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected diagnostic to contain %q, got: %v", want, err)
+		}
+	}
+}
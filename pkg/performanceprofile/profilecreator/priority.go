@@ -0,0 +1,163 @@
+package profilecreator
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/jaypipes/ghw/pkg/cpu"
+	"github.com/jaypipes/ghw/pkg/topology"
+	"k8s.io/utils/cpuset"
+)
+
+// CPUPriority selects which class of core (P-core/high-priority vs.
+// E-core/low-priority on hybrid x86, big vs. LITTLE on ARM) reserved CPUs are
+// drawn from first, via the PreferredCPUPriority option of CalculateCPUSets.
+type CPUPriority string
+
+const (
+	// CPUPriorityNone disables priority-aware selection: cores are treated
+	// uniformly, matching the pre-hybrid-aware behavior.
+	CPUPriorityNone CPUPriority = "none"
+	// CPUPriorityLow reserves from the lowest-capacity cores (E-cores)
+	// first, leaving high-capacity cores (P-cores) for isolated workloads.
+	CPUPriorityLow CPUPriority = "low"
+	// CPUPriorityHigh reserves from the highest-capacity cores (P-cores)
+	// first.
+	CPUPriorityHigh CPUPriority = "high"
+)
+
+// sysCPUCapacityPathFmt is templated with a logical processor id to read the
+// kernel's relative core capacity, when exposed. It is relative to a
+// sysfsRoot rather than an absolute host path; see detectCoreCapacity.
+const sysCPUCapacityPathFmt = "sys/devices/system/cpu/cpu%d/cpu_capacity"
+
+// detectCoreCapacity returns the relative capacity of the core owning the
+// given logical processor id. It reads the kernel's cpu_capacity sysfs
+// attribute, populated on ARM big.LITTLE and hybrid-aware x86 kernels,
+// rooted at sysfsRoot (the extracted must-gather/GHW snapshot directory the
+// rest of this package reads sysfs-shaped files from, or "/" when running
+// directly against live hardware). It returns 0 ("unknown") when the
+// attribute is not available so callers can treat the system as uniform. It
+// is a package variable so tests can substitute a synthetic capacity map
+// instead of reading sysfs.
+//
+// A full Intel CPUID leaf 0x1A hybrid core-type read requires a privileged
+// on-node probe and is out of scope here: the profile creator runs against a
+// must-gather/GHW snapshot rather than live hardware, and the sysfs
+// attribute already covers the hybrid-aware kernels in the field.
+var detectCoreCapacity = func(sysfsRoot string, lpID int) int {
+	raw, err := os.ReadFile(path.Join(sysfsRoot, fmt.Sprintf(sysCPUCapacityPathFmt, lpID)))
+	if err != nil {
+		return 0
+	}
+	capacity, err := strconv.Atoi(strings.TrimSpace(string(raw)))
+	if err != nil {
+		return 0
+	}
+	return capacity
+}
+
+// rankCoresByPriority returns topologyInfoNodes with each node's Cores slice
+// reordered by detected capacity, so that the cpuAccumulator-based selection
+// in getReservedCPUs/getCPUsSequentially/getCPUsSplitAcrossNUMA (which always
+// consumes a node's cores in slice order) picks low-capacity cores (E-cores)
+// first for CPUPriorityLow, or high-capacity cores (P-cores) first for
+// CPUPriorityHigh. Hybrid cores typically share a NUMA node with cores of the
+// other priority, so the reorder happens within each node rather than across
+// them. Cores with equal/unknown capacity keep their original relative order
+// (stable sort). sysfsRoot is the root detectCoreCapacity resolves
+// cpu_capacity from; if no capacity data is found anywhere in
+// topologyInfoNodes (the attribute isn't present under sysfsRoot, e.g. a
+// non-hybrid system or an older must-gather snapshot), this Alerts instead
+// of silently treating preferred as CPUPriorityNone. The input is not
+// mutated; new Node/Cores slices are returned.
+func rankCoresByPriority(topologyInfoNodes []*topology.Node, preferred CPUPriority, sysfsRoot string) []*topology.Node {
+	if preferred == CPUPriorityNone || preferred == "" {
+		return topologyInfoNodes
+	}
+
+	if !anyCapacityDetected(topologyInfoNodes, sysfsRoot) {
+		Alert("preferred CPU priority %q could not be honored: no core capacity data found under %q; treating all cores as uniform", preferred, sysfsRoot)
+	}
+
+	ascending := preferred == CPUPriorityLow
+	out := make([]*topology.Node, 0, len(topologyInfoNodes))
+	for _, node := range topologyInfoNodes {
+		cores := make([]*cpu.ProcessorCore, len(node.Cores))
+		copy(cores, node.Cores)
+
+		// stable insertion sort: a NUMA node's core count is small and we
+		// need to preserve relative order among equal-capacity cores.
+		for i := 1; i < len(cores); i++ {
+			for j := i; j > 0; j-- {
+				ci, cj := coreCapacity(cores[j], sysfsRoot), coreCapacity(cores[j-1], sysfsRoot)
+				less := ci < cj
+				if !ascending {
+					less = ci > cj
+				}
+				if !less {
+					break
+				}
+				cores[j], cores[j-1] = cores[j-1], cores[j]
+			}
+		}
+
+		out = append(out, &topology.Node{ID: node.ID, Cores: cores})
+	}
+	return out
+}
+
+// coreCapacity returns a core's capacity as the average of its logical
+// processors' detected capacity.
+func coreCapacity(core *cpu.ProcessorCore, sysfsRoot string) int {
+	if len(core.LogicalProcessors) == 0 {
+		return 0
+	}
+	total := 0
+	for _, lp := range core.LogicalProcessors {
+		total += detectCoreCapacity(sysfsRoot, lp)
+	}
+	return total / len(core.LogicalProcessors)
+}
+
+// anyCapacityDetected reports whether detectCoreCapacity found a non-zero
+// (i.e. known) capacity for at least one core in topologyInfoNodes.
+func anyCapacityDetected(topologyInfoNodes []*topology.Node, sysfsRoot string) bool {
+	for _, node := range topologyInfoNodes {
+		for _, core := range node.Cores {
+			if coreCapacity(core, sysfsRoot) != 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasMixedCapacity reports whether set spans cores of more than one distinct
+// detected capacity, i.e. a reservation that could not be satisfied from a
+// single priority class of core.
+func hasMixedCapacity(topologyInfoNodes []*topology.Node, set cpuset.CPUSet, sysfsRoot string) bool {
+	seen := map[int]struct{}{}
+	for _, node := range topologyInfoNodes {
+		for _, core := range node.Cores {
+			touchesSet := false
+			for _, lp := range core.LogicalProcessors {
+				if set.Contains(lp) {
+					touchesSet = true
+					break
+				}
+			}
+			if !touchesSet {
+				continue
+			}
+			seen[coreCapacity(core, sysfsRoot)] = struct{}{}
+			if len(seen) > 1 {
+				return true
+			}
+		}
+	}
+	return false
+}
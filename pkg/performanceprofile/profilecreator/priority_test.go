@@ -0,0 +1,110 @@
+package profilecreator
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jaypipes/ghw/pkg/cpu"
+	"github.com/jaypipes/ghw/pkg/topology"
+	"k8s.io/utils/cpuset"
+)
+
+// withSyntheticCapacities swaps detectCoreCapacity for a lookup against a
+// synthetic GHW snapshot's logical-processor-id-to-capacity map, restoring
+// the original on cleanup.
+func withSyntheticCapacities(t *testing.T, capacities map[int]int) {
+	t.Helper()
+	orig := detectCoreCapacity
+	detectCoreCapacity = func(_ string, lpID int) int { return capacities[lpID] }
+	t.Cleanup(func() { detectCoreCapacity = orig })
+}
+
+// hybridNode is a synthetic single-NUMA-node snapshot with two P-cores
+// (capacity 1024, 2 threads each) and two E-cores (capacity 512, 1 thread
+// each), interleaved the way lscpu typically reports them on an Intel
+// Alder Lake-style part.
+func hybridNode() []*topology.Node {
+	return []*topology.Node{
+		{
+			ID: 0,
+			Cores: []*cpu.ProcessorCore{
+				{ID: 0, NumThreads: 2, LogicalProcessors: []int{0, 1}}, // P-core
+				{ID: 1, NumThreads: 1, LogicalProcessors: []int{2}},    // E-core
+				{ID: 2, NumThreads: 2, LogicalProcessors: []int{3, 4}}, // P-core
+				{ID: 3, NumThreads: 1, LogicalProcessors: []int{5}},    // E-core
+			},
+		},
+	}
+}
+
+func hybridCapacities() map[int]int {
+	return map[int]int{0: 1024, 1: 1024, 2: 512, 3: 1024, 4: 1024, 5: 512}
+}
+
+func TestRankCoresByPriorityNone(t *testing.T) {
+	withSyntheticCapacities(t, hybridCapacities())
+	nodes := hybridNode()
+
+	if got := rankCoresByPriority(nodes, CPUPriorityNone, "/"); !reflect.DeepEqual(got, nodes) {
+		t.Fatalf("expected CPUPriorityNone to return the input unchanged, got %+v", got)
+	}
+}
+
+func TestRankCoresByPriorityLowPutsECoresFirst(t *testing.T) {
+	withSyntheticCapacities(t, hybridCapacities())
+
+	ranked := rankCoresByPriority(hybridNode(), CPUPriorityLow, "/")
+	if len(ranked) != 1 {
+		t.Fatalf("expected 1 NUMA node, got %d", len(ranked))
+	}
+
+	gotIDs := coreIDs(ranked[0].Cores)
+	wantIDs := []int{1, 3, 0, 2}
+	if !reflect.DeepEqual(gotIDs, wantIDs) {
+		t.Fatalf("CPUPriorityLow: expected E-cores first %v, got %v", wantIDs, gotIDs)
+	}
+}
+
+func TestRankCoresByPriorityHighPutsPCoresFirst(t *testing.T) {
+	withSyntheticCapacities(t, hybridCapacities())
+
+	ranked := rankCoresByPriority(hybridNode(), CPUPriorityHigh, "/")
+	gotIDs := coreIDs(ranked[0].Cores)
+	wantIDs := []int{0, 2, 1, 3}
+	if !reflect.DeepEqual(gotIDs, wantIDs) {
+		t.Fatalf("CPUPriorityHigh: expected P-cores first %v, got %v", wantIDs, gotIDs)
+	}
+}
+
+func TestRankCoresByPriorityDoesNotMutateInput(t *testing.T) {
+	withSyntheticCapacities(t, hybridCapacities())
+
+	nodes := hybridNode()
+	originalOrder := coreIDs(nodes[0].Cores)
+
+	rankCoresByPriority(nodes, CPUPriorityLow, "/")
+
+	if got := coreIDs(nodes[0].Cores); !reflect.DeepEqual(got, originalOrder) {
+		t.Fatalf("expected input node's Cores slice to be left untouched, got %v", got)
+	}
+}
+
+func TestHasMixedCapacity(t *testing.T) {
+	withSyntheticCapacities(t, hybridCapacities())
+	nodes := hybridNode()
+
+	if got := hasMixedCapacity(nodes, cpuset.New(0, 1), "/"); got {
+		t.Fatalf("expected a reservation confined to a single P-core to not be mixed-capacity")
+	}
+	if got := hasMixedCapacity(nodes, cpuset.New(0, 1, 2), "/"); !got {
+		t.Fatalf("expected a reservation spanning a P-core and an E-core to be mixed-capacity")
+	}
+}
+
+func coreIDs(cores []*cpu.ProcessorCore) []int {
+	ids := make([]int, 0, len(cores))
+	for _, c := range cores {
+		ids = append(ids, c.ID)
+	}
+	return ids
+}
@@ -103,13 +103,78 @@ type systemInfo struct {
 	CpuInfo      *extendedCPUInfo
 	TopologyInfo *topology.Info
 	HtEnabled    bool
+	// SysfsRoot is the root directory rankCoresByPriority/
+	// reorderCoresForL3Packing resolve sysfs-shaped paths (cpu_capacity,
+	// the L3 shared_cpu_list) from: "/" when CpuInfo/TopologyInfo were
+	// gathered directly from live hardware, or the root of an extracted
+	// must-gather/GHW snapshot otherwise. It defaults to "/" when left
+	// unset.
+	SysfsRoot string
 }
 
+// CPUExclusivePolicy controls whether reserved CPUs are allowed to share a
+// physical core with isolated CPUs when hyperthreading is on.
+type CPUExclusivePolicy string
+
+const (
+	// CPUSharedPhysicalCore is the historical behavior: reserved CPUs are
+	// picked thread-by-thread and may end up sharing a physical core (and
+	// therefore its L1/L2 cache) with an isolated CPU.
+	CPUSharedPhysicalCore CPUExclusivePolicy = "shared"
+	// PCPULevelExclusive always reserves every sibling of a chosen physical
+	// core together, so no isolated workload ends up sharing an L1/L2 with a
+	// reserved thread. This can round reservedCPUCount up to the nearest
+	// multiple of the SMT level.
+	PCPULevelExclusive CPUExclusivePolicy = "pcpu-level-exclusive"
+)
+
 // Calculates the resevered, isolated and offlined cpuSets.
-func CalculateCPUSets(systemInfo *systemInfo, reservedCPUCount int, offlinedCPUCount int, splitReservedCPUsAcrossNUMA bool, disableHTFlag bool, highPowerConsumptionMode bool) (cpuset.CPUSet, cpuset.CPUSet, cpuset.CPUSet, error) {
+//
+// hideHyperthreads implements the "hide-hyperthreads" mode: SMT stays
+// enabled on the node (no nosmt kernel arg is emitted regardless of
+// disableHTFlag), reserved CPUs are chosen normally, but the isolated set is
+// restricted to the first sibling of each otherwise-isolated core, with the
+// second siblings moved into the offlined set. This gives latency-sensitive
+// pods effective single-thread cores without losing SMT throughput for
+// reserved/system work.
+//
+// preferredCPUPriority steers reservation on hybrid/heterogeneous systems
+// (Intel P-core/E-core, ARM big.LITTLE): CPUPriorityLow reserves from
+// low-capacity cores first, CPUPriorityHigh from high-capacity cores first,
+// and CPUPriorityNone (or empty) treats all cores uniformly. Isolated pinning
+// naturally receives the complementary set.
+//
+// distributionPolicy additionally controls how cores are ordered before
+// reserved-CPU selection: DistributionBalancedNUMA is equivalent to
+// splitReservedCPUsAcrossNUMA, and DistributionSpreadL3Cache groups cores by
+// shared L3 domain so a reservation stays within as few L3 domains as
+// possible. splitOfflinedCPUsAcrossNUMA gives the offlined set the same
+// per-NUMA balancing splitReservedCPUsAcrossNUMA gives the reserved set;
+// DistributionBalancedNUMA turns on both. DistributionSpreadL3Cache does not
+// affect getOfflinedCPUs.
+//
+// preallocatedCPUs are logical processors already committed to other
+// workloads (see LoadPreallocatedCPUs) that must not be reserved or offlined
+// again. If reservedCPUCount cannot be satisfied without reusing one of
+// them, CalculateCPUSets returns a diagnostic naming the conflicting
+// cores/sockets instead of silently reserving over them.
+//
+// CalculateCPUSets and LoadPreallocatedCPUs are the reservation-math core of
+// the performance-profile-creator tool: the CLI flag parsing that feeds
+// these parameters in (including a --preallocated-cpus flag for
+// LoadPreallocatedCPUs) and the PerformanceProfile CRD/schema that the
+// result would be rendered into live in cmd/performance-profile-creator and
+// pkg/apis/performanceprofile/v2, neither of which is part of this
+// checkout. No CLI or CRD wiring is added here pending that support landing.
+func CalculateCPUSets(systemInfo *systemInfo, reservedCPUCount int, offlinedCPUCount int, splitReservedCPUsAcrossNUMA bool, disableHTFlag bool, highPowerConsumptionMode bool, exclusivePolicy CPUExclusivePolicy, hideHyperthreads bool, preferredCPUPriority CPUPriority, distributionPolicy DistributionPolicy, splitOfflinedCPUsAcrossNUMA bool, preallocatedCPUs cpuset.CPUSet) (cpuset.CPUSet, cpuset.CPUSet, cpuset.CPUSet, error) {
 	topologyInfo := systemInfo.TopologyInfo
 	htEnabled := systemInfo.HtEnabled
 
+	if hideHyperthreads && disableHTFlag {
+		Alert("hide-hyperthreads and disable-ht are mutually exclusive; ignoring disable-ht")
+		disableHTFlag = false
+	}
+
 	// Need to update Topology info to avoid using sibling Logical processors
 	// if user want to "disable" them in the kernel
 	updatedTopologyInfo, err := updateTopologyInfo(topologyInfo, disableHTFlag, systemInfo.HtEnabled)
@@ -117,7 +182,11 @@ func CalculateCPUSets(systemInfo *systemInfo, reservedCPUCount int, offlinedCPUC
 		return cpuset.CPUSet{}, cpuset.CPUSet{}, cpuset.CPUSet{}, err
 	}
 
-	updatedExtCPUInfo, err := updateExtendedCPUInfo(systemInfo.CpuInfo, cpuset.CPUSet{}, disableHTFlag, htEnabled)
+	// Seed the bookkeeping maps with any CPUs already committed elsewhere so
+	// getOfflinedCPUs skips them when picking CPUs to offline (the final
+	// offlined set is checked against them again below); getReservedCPUs is checked against
+	// preallocatedCPUs separately below since it doesn't consult extCpuInfo.
+	updatedExtCPUInfo, err := updateExtendedCPUInfo(systemInfo.CpuInfo, preallocatedCPUs, disableHTFlag, htEnabled)
 	if err != nil {
 		return cpuset.CPUSet{}, cpuset.CPUSet{}, cpuset.CPUSet{}, err
 	}
@@ -136,19 +205,70 @@ func CalculateCPUSets(systemInfo *systemInfo, reservedCPUCount int, offlinedCPUC
 		return cpuset.CPUSet{}, cpuset.CPUSet{}, cpuset.CPUSet{}, fmt.Errorf("please ensure that reserved-cpu-count plus offlined-cpu-count should be in the range [0,%d]", cpuInfo.TotalThreads-1)
 	}
 
+	// On hybrid/heterogeneous systems, reorder each NUMA node's cores by
+	// detected capacity so reservation picks E-cores first (low) or P-cores
+	// first (high); isolated pinning naturally ends up with the complement.
+	// DistributionSpreadL3Cache reorders on top of that so cores sharing an
+	// L3 domain are drawn together; when both are set, the L3 grouping wins
+	// for the final ordering.
+	sysfsRoot := systemInfo.SysfsRoot
+	if sysfsRoot == "" {
+		sysfsRoot = "/"
+	}
+	rankedTopologyInfoNodes := updatedTopologyInfo.Nodes
+	if preferredCPUPriority != CPUPriorityNone && preferredCPUPriority != "" {
+		rankedTopologyInfoNodes = rankCoresByPriority(rankedTopologyInfoNodes, preferredCPUPriority, sysfsRoot)
+	}
+	if distributionPolicy == DistributionSpreadL3Cache {
+		rankedTopologyInfoNodes = reorderCoresForL3Packing(rankedTopologyInfoNodes, sysfsRoot)
+	}
+	rankedTopologyInfo := &topology.Info{
+		Architecture: updatedTopologyInfo.Architecture,
+		Nodes:        rankedTopologyInfoNodes,
+	}
+
+	// DistributionBalancedNUMA is an explicit name for the existing
+	// splitReservedCPUsAcrossNUMA behavior, applied to the offlined set too.
+	splitReservedCPUsAcrossNUMA = splitReservedCPUsAcrossNUMA || distributionPolicy == DistributionBalancedNUMA
+	splitOfflinedCPUsAcrossNUMA = splitOfflinedCPUsAcrossNUMA || distributionPolicy == DistributionBalancedNUMA
+
 	// Calculate reserved cpus.
-	reserved, err := getReservedCPUs(updatedTopologyInfo, reservedCPUCount, splitReservedCPUsAcrossNUMA, disableHTFlag, htEnabled)
+	reserved, err := getReservedCPUs(rankedTopologyInfo, reservedCPUCount, splitReservedCPUsAcrossNUMA, disableHTFlag, htEnabled)
 	if err != nil {
 		return cpuset.CPUSet{}, cpuset.CPUSet{}, cpuset.CPUSet{}, err
 	}
 
+	if conflict := reserved.Intersection(preallocatedCPUs); !conflict.IsEmpty() {
+		return cpuset.CPUSet{}, cpuset.CPUSet{}, cpuset.CPUSet{}, newPreallocatedConflictError(updatedExtCPUInfo, conflict)
+	}
+
+	if preferredCPUPriority != CPUPriorityNone && preferredCPUPriority != "" && hasMixedCapacity(updatedTopologyInfo.Nodes, reserved, sysfsRoot) {
+		Alert("preferred CPU priority %q could not be fully honored: the reserved set spans cores of more than one detected capacity", preferredCPUPriority)
+	}
+
+	if exclusivePolicy == PCPULevelExclusive {
+		// Pull in every sibling of a core we already reserved a thread from,
+		// so isolated workloads never land on the other half of a reserved
+		// physical core.
+		reserved = expandToFullCores(updatedTopologyInfo.Nodes, reserved)
+		Alert("PCPULevelExclusive: reserved set expanded to full physical cores: %s", reserved.String())
+
+		// The expansion above can pull in a sibling that LoadPreallocatedCPUs
+		// already committed to another workload, even though the pre-expansion
+		// reserved set didn't conflict. Re-check so PCPULevelExclusive can
+		// never silently hand out a preallocated CPU.
+		if conflict := reserved.Intersection(preallocatedCPUs); !conflict.IsEmpty() {
+			return cpuset.CPUSet{}, cpuset.CPUSet{}, cpuset.CPUSet{}, newPreallocatedConflictError(updatedExtCPUInfo, conflict)
+		}
+	}
+
 	updatedExtCPUInfo, err = updateExtendedCPUInfo(updatedExtCPUInfo, reserved, disableHTFlag, htEnabled)
 	if err != nil {
 		return cpuset.CPUSet{}, cpuset.CPUSet{}, cpuset.CPUSet{}, err
 	}
 	//Calculate offlined cpus
 	// note this takes into account the reserved cpus from the step above
-	offlined, err := getOfflinedCPUs(updatedExtCPUInfo, offlinedCPUCount, disableHTFlag, htEnabled, highPowerConsumptionMode)
+	offlined, err := getOfflinedCPUs(updatedExtCPUInfo, updatedTopologyInfo.Nodes, offlinedCPUCount, disableHTFlag, htEnabled, highPowerConsumptionMode, splitOfflinedCPUsAcrossNUMA, exclusivePolicy)
 	if err != nil {
 		return cpuset.CPUSet{}, cpuset.CPUSet{}, cpuset.CPUSet{}, err
 	}
@@ -161,9 +281,56 @@ func CalculateCPUSets(systemInfo *systemInfo, reservedCPUCount int, offlinedCPUC
 		return cpuset.CPUSet{}, cpuset.CPUSet{}, cpuset.CPUSet{}, err
 	}
 
+	if hideHyperthreads {
+		isolated, offlined = hideIsolatedHyperthreads(updatedTopologyInfo.Nodes, reserved, isolated, offlined)
+		Alert("hide-hyperthreads: isolated set restricted to %s, second siblings moved to offlined", isolated.String())
+	}
+
+	// PCPULevelExclusive's full-core expansion and hide-hyperthreads can both
+	// move a preallocated sibling into the offlined set.
+	if conflict := offlined.Intersection(preallocatedCPUs); !conflict.IsEmpty() {
+		return cpuset.CPUSet{}, cpuset.CPUSet{}, cpuset.CPUSet{}, newPreallocatedConflictError(updatedExtCPUInfo, conflict)
+	}
+
 	return reserved, isolated, offlined, nil
 }
 
+// hideIsolatedHyperthreads restricts isolated to the first logical processor
+// of each core that is entirely isolated (i.e. none of its siblings are
+// reserved or already offlined), moving every other sibling of such cores
+// into the returned offlined set. Cores that are not entirely isolated (e.g.
+// partially reserved) are left untouched.
+func hideIsolatedHyperthreads(topologyInfoNodes []*topology.Node, reserved, isolated, offlined cpuset.CPUSet) (cpuset.CPUSet, cpuset.CPUSet) {
+	// Start from the untouched sets and only move the extra siblings of a
+	// fully isolated core into offlined, so a partially reserved core's lone
+	// isolated sibling is neither dropped nor offlined.
+	newIsolated := isolated
+	newOfflined := offlined
+
+	for _, node := range topologyInfoNodes {
+		for _, core := range node.Cores {
+			fullyIsolated := true
+			for _, lp := range core.LogicalProcessors {
+				if reserved.Contains(lp) || offlined.Contains(lp) {
+					fullyIsolated = false
+					break
+				}
+			}
+			if !fullyIsolated {
+				continue
+			}
+			for index, lp := range core.LogicalProcessors {
+				if index == 0 {
+					continue
+				}
+				newIsolated = newIsolated.Difference(cpuset.New(lp))
+				newOfflined = newOfflined.Union(cpuset.New(lp))
+			}
+		}
+	}
+	return newIsolated, newOfflined
+}
+
 // Calculates Isolated cpuSet as the difference between all the cpus in the topology and those already chosen as reserved or offlined.
 // all cpus thar are not offlined or reserved belongs to the isolated cpuSet
 func getIsolatedCPUs(topologyInfoNodes []*topology.Node, reserved, offlined cpuset.CPUSet) (cpuset.CPUSet, error) {
@@ -182,7 +349,15 @@ func AreAllLogicalProcessorsFromSocketUnused(extCpuInfo *extendedCPUInfo, socket
 	}
 }
 
-func getOfflinedCPUs(extCpuInfo *extendedCPUInfo, offlinedCPUCount int, disableHTFlag bool, htEnabled bool, highPowerConsumption bool) (cpuset.CPUSet, error) {
+func getOfflinedCPUs(extCpuInfo *extendedCPUInfo, topologyInfoNodes []*topology.Node, offlinedCPUCount int, disableHTFlag bool, htEnabled bool, highPowerConsumption bool, splitAcrossNUMA bool, exclusivePolicy CPUExclusivePolicy) (cpuset.CPUSet, error) {
+	if splitAcrossNUMA {
+		offlined, err := getOfflinedCPUsSplitAcrossNUMA(extCpuInfo, topologyInfoNodes, offlinedCPUCount, highPowerConsumption)
+		if err != nil {
+			return cpuset.CPUSet{}, err
+		}
+		return expandOfflinedForExclusivePolicy(topologyInfoNodes, offlined, exclusivePolicy), nil
+	}
+
 	offlined := newCPUAccumulator()
 	lpOfflined := 0
 
@@ -237,6 +412,77 @@ func getOfflinedCPUs(extCpuInfo *extendedCPUInfo, offlinedCPUCount int, disableH
 		}
 	}
 
+	if lpOfflined < offlinedCPUCount {
+		Alert("could not offline enough logical processors (required:%d, offlined:%d)", offlinedCPUCount, lpOfflined)
+	}
+	return expandOfflinedForExclusivePolicy(topologyInfoNodes, offlined.Result(), exclusivePolicy), nil
+}
+
+// expandOfflinedForExclusivePolicy mirrors expandToFullCores' reserved-side
+// behavior onto the offlined set under PCPULevelExclusive: by the time
+// getOfflinedCPUs runs, any core PCPULevelExclusive touched has already been
+// reserved in full (the reserved-set expansion above runs first), so pulling
+// in the rest of a partially offlined core here can never reclaim a reserved
+// CPU - it only ever rounds offlinedCPUCount up, the same way reservation
+// can.
+func expandOfflinedForExclusivePolicy(topologyInfoNodes []*topology.Node, offlined cpuset.CPUSet, exclusivePolicy CPUExclusivePolicy) cpuset.CPUSet {
+	if exclusivePolicy != PCPULevelExclusive {
+		return offlined
+	}
+	expanded := expandToFullCores(topologyInfoNodes, offlined)
+	Alert("PCPULevelExclusive: offlined set expanded to full physical cores: %s", expanded.String())
+	return expanded
+}
+
+// getOfflinedCPUsSplitAcrossNUMA distributes offlinedCPUCount evenly across
+// NUMA nodes, mirroring getCPUsSplitAcrossNUMA's per-node budget math, so
+// offlining stays balanced the same way reservation can be. Unlike
+// getOfflinedCPUs it does not attempt the "offline a whole socket" shortcut
+// first, since sockets need not align with NUMA nodes: it goes straight to
+// sibling-thread-first, then any-cpu, offlining scoped to each node's cores.
+// In highPowerConsumption mode the sibling-thread-first pass is skipped and
+// only the any-cpu pass runs.
+func getOfflinedCPUsSplitAcrossNUMA(extCpuInfo *extendedCPUInfo, topologyInfoNodes []*topology.Node, offlinedCPUCount int, highPowerConsumption bool) (cpuset.CPUSet, error) {
+	offlined := newCPUAccumulator()
+	lpOfflined := 0
+
+	numaNodeNum := len(topologyInfoNodes)
+	max := 0
+	perNuma := offlinedCPUCount / numaNodeNum
+	remainder := offlinedCPUCount % numaNodeNum
+	if remainder != 0 {
+		Alert("The offlined CPUs cannot be split equally across NUMA Nodes")
+	}
+
+	for numaID, node := range topologyInfoNodes {
+		if remainder != 0 {
+			max = (numaID+1)*perNuma + (numaNodeNum - remainder)
+			remainder--
+		} else {
+			max = max + perNuma
+		}
+
+		if !highPowerConsumption {
+			acc, err := offlined.AddCoresWithFilter(max, node.Cores, func(index, lpID int) bool {
+				return filterFirstLogicalProcessorInCore(index, lpID) && !IsLogicalProcessorUsed(extCpuInfo, lpID)
+			})
+			if err != nil {
+				return cpuset.CPUSet{}, err
+			}
+			lpOfflined += acc
+		}
+
+		if lpOfflined < max {
+			acc, err := offlined.AddCoresWithFilter(max, node.Cores, func(index, lpID int) bool {
+				return !IsLogicalProcessorUsed(extCpuInfo, lpID)
+			})
+			if err != nil {
+				return cpuset.CPUSet{}, err
+			}
+			lpOfflined += acc
+		}
+	}
+
 	if lpOfflined < offlinedCPUCount {
 		Alert("could not offline enough logical processors (required:%d, offlined:%d)", offlinedCPUCount, lpOfflined)
 	}
@@ -384,6 +630,34 @@ func getCPUsSequentially(reservedCPUCount int, htEnabled bool, topologyInfoNodes
 	return reservedCPUs.Result(), nil
 }
 
+// expandToFullCores returns a superset of set that additionally contains,
+// for every physical core with at least one logical processor in set, all of
+// that core's other logical processors. It is used by PCPULevelExclusive to
+// guarantee a reserved set never straddles a core with an isolated CPU.
+func expandToFullCores(topologyInfoNodes []*topology.Node, set cpuset.CPUSet) cpuset.CPUSet {
+	expanded := newCPUAccumulator()
+	for _, node := range topologyInfoNodes {
+		for _, core := range node.Cores {
+			touchesCore := false
+			for _, lp := range core.LogicalProcessors {
+				if set.Contains(lp) {
+					touchesCore = true
+					break
+				}
+			}
+			if !touchesCore {
+				continue
+			}
+			if _, err := expanded.AddCores(allCores, []*cpu.ProcessorCore{core}); err != nil {
+				// AddCores only fails once the accumulator is finalized,
+				// which cannot happen here.
+				continue
+			}
+		}
+	}
+	return expanded.Result()
+}
+
 func totalCPUSetFromTopology(topologyInfoNodes []*topology.Node) (cpuset.CPUSet, error) {
 	totalCPUs := newCPUAccumulator()
 	for _, node := range topologyInfoNodes {
@@ -480,10 +754,13 @@ func ensureSameTopology(topology1, topology2 *topology.Info, tols toleration.Set
 	return nil
 }
 
-// GetAdditionalKernelArgs returns a set of kernel parameters based on configuration
-func GetAdditionalKernelArgs(disableHT bool) []string {
+// GetAdditionalKernelArgs returns a set of kernel parameters based on
+// configuration. hideHyperthreads takes precedence over disableHT: when set,
+// SMT must stay enabled on the node for the hide-hyperthreads isolated CPU
+// mode to work, so nosmt is never emitted.
+func GetAdditionalKernelArgs(disableHT bool, hideHyperthreads bool) []string {
 	var kernelArgs []string
-	if disableHT {
+	if disableHT && !hideHyperthreads {
 		kernelArgs = append(kernelArgs, noSMTKernelArg)
 	}
 	sort.Strings(kernelArgs)
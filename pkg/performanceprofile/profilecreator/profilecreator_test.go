@@ -0,0 +1,220 @@
+package profilecreator
+
+import (
+	"testing"
+
+	"github.com/jaypipes/ghw/pkg/cpu"
+	"github.com/jaypipes/ghw/pkg/topology"
+	"k8s.io/utils/cpuset"
+)
+
+// twoCoreNode is a single-NUMA-node snapshot with two 2-thread cores:
+// core 0 -> {0, 1}, core 1 -> {2, 3}.
+func twoCoreNode() []*topology.Node {
+	return []*topology.Node{
+		{
+			ID: 0,
+			Cores: []*cpu.ProcessorCore{
+				{ID: 0, NumThreads: 2, LogicalProcessors: []int{0, 1}},
+				{ID: 1, NumThreads: 2, LogicalProcessors: []int{2, 3}},
+			},
+		},
+	}
+}
+
+func TestExpandToFullCoresPullsInSiblingOfPartiallyReservedCore(t *testing.T) {
+	nodes := twoCoreNode()
+
+	got := expandToFullCores(nodes, cpuset.New(0))
+	want := cpuset.New(0, 1)
+	if !got.Equals(want) {
+		t.Fatalf("expandToFullCores(%v) = %s, want %s", cpuset.New(0), got, want)
+	}
+}
+
+func TestExpandToFullCoresLeavesUntouchedCoresAlone(t *testing.T) {
+	nodes := twoCoreNode()
+
+	got := expandToFullCores(nodes, cpuset.New(0, 1))
+	want := cpuset.New(0, 1)
+	if !got.Equals(want) {
+		t.Fatalf("expandToFullCores(%v) = %s, want %s", cpuset.New(0, 1), got, want)
+	}
+}
+
+func TestHideIsolatedHyperthreadsLeavesPartiallyReservedCoreUntouched(t *testing.T) {
+	nodes := twoCoreNode()
+
+	// core 0 is partially reserved (cpu 0), so its lone isolated sibling
+	// (cpu 1) must stay isolated rather than being dropped or offlined.
+	// core 1 is fully isolated, so its second thread (cpu 3) should move to
+	// offlined, leaving only cpu 2 isolated.
+	reserved := cpuset.New(0)
+	isolated := cpuset.New(1, 2, 3)
+	offlined := cpuset.New()
+
+	gotIsolated, gotOfflined := hideIsolatedHyperthreads(nodes, reserved, isolated, offlined)
+
+	wantIsolated := cpuset.New(1, 2)
+	wantOfflined := cpuset.New(3)
+	if !gotIsolated.Equals(wantIsolated) {
+		t.Fatalf("isolated = %s, want %s", gotIsolated, wantIsolated)
+	}
+	if !gotOfflined.Equals(wantOfflined) {
+		t.Fatalf("offlined = %s, want %s", gotOfflined, wantOfflined)
+	}
+}
+
+func TestHideIsolatedHyperthreadsPreservesPreviouslyOfflinedCPUs(t *testing.T) {
+	nodes := twoCoreNode()
+
+	reserved := cpuset.New()
+	isolated := cpuset.New(1, 2, 3)
+	offlined := cpuset.New(0)
+
+	gotIsolated, gotOfflined := hideIsolatedHyperthreads(nodes, reserved, isolated, offlined)
+
+	// core 0 is not fully isolated (cpu 0 is offlined), so cpu 1 stays
+	// isolated; core 1 is fully isolated so cpu 3 joins the already
+	// offlined cpu 0.
+	wantIsolated := cpuset.New(1, 2)
+	wantOfflined := cpuset.New(0, 3)
+	if !gotIsolated.Equals(wantIsolated) {
+		t.Fatalf("isolated = %s, want %s", gotIsolated, wantIsolated)
+	}
+	if !gotOfflined.Equals(wantOfflined) {
+		t.Fatalf("offlined = %s, want %s", gotOfflined, wantOfflined)
+	}
+}
+
+// fourCoreTwoNUMANodes is a 2-NUMA-node snapshot, each node with two
+// 2-thread cores: NUMA 0 -> core 0 {0,1}, core 1 {2,3}; NUMA 1 -> core 2
+// {4,5}, core 3 {6,7}.
+func fourCoreTwoNUMANodes() []*topology.Node {
+	return []*topology.Node{
+		{
+			ID: 0,
+			Cores: []*cpu.ProcessorCore{
+				{ID: 0, NumThreads: 2, LogicalProcessors: []int{0, 1}},
+				{ID: 1, NumThreads: 2, LogicalProcessors: []int{2, 3}},
+			},
+		},
+		{
+			ID: 1,
+			Cores: []*cpu.ProcessorCore{
+				{ID: 2, NumThreads: 2, LogicalProcessors: []int{4, 5}},
+				{ID: 3, NumThreads: 2, LogicalProcessors: []int{6, 7}},
+			},
+		},
+	}
+}
+
+// cpuInfoFromNodes builds the extendedCPUInfo/topology.Info pair
+// CalculateCPUSets expects, treating every NUMA node as its own socket.
+func cpuInfoFromNodes(nodes []*topology.Node) (*extendedCPUInfo, *topology.Info) {
+	var processors []*cpu.Processor
+	var totalCores, totalThreads uint32
+	for _, node := range nodes {
+		var threads uint32
+		for _, core := range node.Cores {
+			threads += uint32(len(core.LogicalProcessors))
+		}
+		processors = append(processors, &cpu.Processor{
+			ID:         node.ID,
+			NumCores:   uint32(len(node.Cores)),
+			NumThreads: threads,
+			Cores:      node.Cores,
+		})
+		totalCores += uint32(len(node.Cores))
+		totalThreads += threads
+	}
+	extCPUInfo := &extendedCPUInfo{
+		CpuInfo: &cpu.Info{
+			Processors:   processors,
+			TotalCores:   totalCores,
+			TotalThreads: totalThreads,
+		},
+		NumLogicalProcessorsUsed: map[int]int{},
+		LogicalProcessorsUsed:    map[int]struct{}{},
+	}
+	return extCPUInfo, &topology.Info{Nodes: nodes}
+}
+
+func TestCalculateCPUSetsRejectsPreallocatedConflictIntroducedByPCPUExpansion(t *testing.T) {
+	nodes := fourCoreTwoNUMANodes()
+	extCPUInfo, topologyInfo := cpuInfoFromNodes(nodes)
+	sysInfo := &systemInfo{
+		CpuInfo:      extCPUInfo,
+		TopologyInfo: topologyInfo,
+		HtEnabled:    false,
+	}
+
+	// One reserved CPU per NUMA node picks a single thread from each NUMA's
+	// first core: cpu 0 and cpu 4. Neither conflicts with the preallocated
+	// snapshot on its own, but PCPULevelExclusive's full-core expansion pulls
+	// in cpu 1 - which the snapshot already committed elsewhere - so the
+	// conflict must be caught after expansion, not before.
+	preallocated := cpuset.New(1)
+
+	_, _, _, err := CalculateCPUSets(sysInfo, 2, 0, true, false, false, PCPULevelExclusive, false, CPUPriorityNone, "", false, preallocated)
+	if err == nil {
+		t.Fatalf("expected a preallocated-CPU conflict error once PCPULevelExclusive expands reserved to include cpu 1, got nil")
+	}
+}
+
+func TestCalculateCPUSetsAllowsPCPUExpansionWithoutConflict(t *testing.T) {
+	nodes := fourCoreTwoNUMANodes()
+	extCPUInfo, topologyInfo := cpuInfoFromNodes(nodes)
+	sysInfo := &systemInfo{
+		CpuInfo:      extCPUInfo,
+		TopologyInfo: topologyInfo,
+		HtEnabled:    false,
+	}
+
+	reserved, _, _, err := CalculateCPUSets(sysInfo, 2, 0, true, false, false, PCPULevelExclusive, false, CPUPriorityNone, "", false, cpuset.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := cpuset.New(0, 1, 4, 5)
+	if !reserved.Equals(want) {
+		t.Fatalf("reserved = %s, want %s", reserved, want)
+	}
+}
+
+func TestCalculateCPUSetsExpandsOfflinedToFullCoresUnderPCPUExclusive(t *testing.T) {
+	nodes := fourCoreTwoNUMANodes()
+	extCPUInfo, topologyInfo := cpuInfoFromNodes(nodes)
+	sysInfo := &systemInfo{
+		CpuInfo:      extCPUInfo,
+		TopologyInfo: topologyInfo,
+		HtEnabled:    false,
+	}
+
+	// 2 reserved cpus split across NUMA (one per node) expand under
+	// PCPULevelExclusive to cpu 0, 1, 4, 5 (see
+	// TestCalculateCPUSetsAllowsPCPUExpansionWithoutConflict). The single
+	// requested offlined cpu can then only come from core 1 {2, 3} or core 3
+	// {6, 7}; PCPULevelExclusive must pull in its sibling too, rather than
+	// leaving it isolated.
+	reserved, isolated, offlined, err := CalculateCPUSets(sysInfo, 2, 1, true, false, false, PCPULevelExclusive, false, CPUPriorityNone, "", false, cpuset.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantReserved := cpuset.New(0, 1, 4, 5)
+	if !reserved.Equals(wantReserved) {
+		t.Fatalf("reserved = %s, want %s", reserved, wantReserved)
+	}
+	if offlined.Size() != 2 {
+		t.Fatalf("offlined = %s, want a full physical core (2 cpus), got %d", offlined, offlined.Size())
+	}
+	if !offlined.Intersection(reserved).IsEmpty() {
+		t.Fatalf("offlined %s overlaps reserved %s", offlined, reserved)
+	}
+	if !isolated.Intersection(offlined).IsEmpty() {
+		t.Fatalf("isolated %s overlaps offlined %s", isolated, offlined)
+	}
+	if isolated.Union(offlined).Union(reserved).Size() != 8 {
+		t.Fatalf("reserved/isolated/offlined do not cover every cpu: reserved=%s isolated=%s offlined=%s", reserved, isolated, offlined)
+	}
+}
@@ -6,12 +6,14 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -42,6 +44,8 @@ import (
 	"github.com/openshift/cluster-node-tuning-operator/test/e2e/performanceprofile/functests/utils/label"
 	testlog "github.com/openshift/cluster-node-tuning-operator/test/e2e/performanceprofile/functests/utils/log"
 	"github.com/openshift/cluster-node-tuning-operator/test/e2e/performanceprofile/functests/utils/nodes"
+	"github.com/openshift/cluster-node-tuning-operator/test/e2e/performanceprofile/functests/utils/parallel"
+	"github.com/openshift/cluster-node-tuning-operator/test/e2e/performanceprofile/functests/utils/podresources"
 	"github.com/openshift/cluster-node-tuning-operator/test/e2e/performanceprofile/functests/utils/pods"
 	"github.com/openshift/cluster-node-tuning-operator/test/e2e/performanceprofile/functests/utils/profiles"
 )
@@ -71,6 +75,28 @@ type Process struct {
 type Annotations struct {
 	ContainerName string `json:"io.kubernetes.container.name"`
 	PodName       string `json:"io.kubernetes.pod.name"`
+	Workload      string `json:"target.workload.openshift.io/management"`
+}
+
+// podLifecycleResult is one pod's outcome from a parallel create/wait/
+// inspect cycle run through the parallel package's worker pool.
+type podLifecycleResult struct {
+	Index             int
+	Pod               *corev1.Pod
+	Node              string
+	UID               types.UID
+	CPUs              cpuset.CPUSet
+	SMTAlignmentError bool
+	Err               error
+}
+
+// podName returns a stable identifier for a soak-test result even when Pod
+// creation itself failed and left Pod nil.
+func (r podLifecycleResult) podName() string {
+	if r.Pod != nil {
+		return r.Pod.Name
+	}
+	return fmt.Sprintf("soak-pod-%d (create failed)", r.Index)
 }
 
 type ContainerConfig struct {
@@ -78,6 +104,34 @@ type ContainerConfig struct {
 	Hostname    string         `json:"hostname"`
 	Annotations Annotations    `json:"annotations"`
 	Linux       LinuxResources `json:"linux"`
+
+	// rawAnnotations keeps every annotation CRI-O wrote for this container,
+	// including the per-container resources.workload.openshift.io/<name>
+	// keys that Annotations above does not have a fixed field for.
+	rawAnnotations map[string]string
+}
+
+// WorkloadResourceAnnotation is the cpushares/cpulimit (millicores) payload
+// CRI-O carries in a management pod's per-container
+// resources.workload.openshift.io/<name> annotation.
+type WorkloadResourceAnnotation struct {
+	CPUShares int `json:"cpushares"`
+	CPULimit  int `json:"cpulimit"`
+}
+
+// getWorkloadResourceAnnotation parses the
+// resources.workload.openshift.io/<containerName> annotation out of config.
+func (config *ContainerConfig) getWorkloadResourceAnnotation(containerName string) (*WorkloadResourceAnnotation, error) {
+	key := fmt.Sprintf("resources.workload.openshift.io/%s", containerName)
+	raw, ok := config.rawAnnotations[key]
+	if !ok {
+		return nil, fmt.Errorf("config.json has no %q annotation", key)
+	}
+	var workload WorkloadResourceAnnotation
+	if err := json.Unmarshal([]byte(raw), &workload); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %q annotation %q: %w", key, raw, err)
+	}
+	return &workload, nil
 }
 
 var _ = Describe("[rfe_id:27363][performance] CPU Management", Ordered, func() {
@@ -312,6 +366,20 @@ var _ = Describe("[rfe_id:27363][performance] CPU Management", Ordered, func() {
 		)
 	})
 
+	Describe("Verification against the PodResources allocatable API", Label(string(label.Tier0)), func() {
+		It("[test_id:75527] kubelet's allocatable CPU pool matches the profile's reserved/isolated split", func(ctx context.Context) {
+			By("querying GetAllocatableResources on the worker-RT node")
+			allocatable, err := podresources.GetAllocatableResources(ctx, workerRTNode)
+			Expect(err).ToNot(HaveOccurred(), "failed to query the allocatable resources from %q", workerRTNode.Name)
+
+			allocatableCPUSet := cpuset.New(allocatable.CPUIDs...)
+			Expect(allocatableCPUSet.Intersection(reservedCPUSet).IsEmpty()).To(BeTrue(),
+				"kubelet reports reserved cpus %s as allocatable to the CPU manager on %q", reservedCPUSet.Intersection(allocatableCPUSet), workerRTNode.Name)
+			Expect(isolatedCPUSet.Difference(allocatableCPUSet).IsEmpty()).To(BeTrue(),
+				"isolated cpus %s are missing from the kubelet's allocatable pool on %q", isolatedCPUSet.Difference(allocatableCPUSet), workerRTNode.Name)
+		})
+	})
+
 	Describe("Verification of cpu_manager_state file", Label(string(label.Tier0)), func() {
 		var testpod *corev1.Pod
 		BeforeEach(func() {
@@ -400,6 +468,22 @@ var _ = Describe("[rfe_id:27363][performance] CPU Management", Ordered, func() {
 				Expect(cpuManagerCpusetBeforeRestart).To(Equal(cpuManagerCpusetAfterRestart))
 			})
 		})
+
+		// This suite has no coverage for a pre-allocation API that reserves
+		// isolated CPUs for a named workload ahead of pod creation (a
+		// CPUReservation CRD, a per-node allocation ledger, and a mutating
+		// webhook rewriting cpuset annotations to the reserved IDs).
+
+		// This suite has no coverage for re-pinning already-running guaranteed
+		// pods' cgroup cpuset.cpus in place when only Spec.CPU.Reserved/Isolated
+		// changes (instead of a MachineConfig rollout that drains the node); see
+		// the "kubelet restart" case above for the coverage this suite has of
+		// cpu_manager_state stability.
+
+		// This suite has no coverage for a per-pod IRQ affinity policy (an
+		// annotation or CRD field letting a workload pin its device interrupts
+		// to a subset of its own exclusive CPUs, rather than the node-wide
+		// GloballyDisableIrqLoadBalancing all-or-nothing switch).
 	})
 
 	Describe("Verification that IRQ load balance can be disabled per POD", Label(string(label.Tier0)), func() {
@@ -504,59 +588,14 @@ var _ = Describe("[rfe_id:27363][performance] CPU Management", Ordered, func() {
 		})
 
 		It("[test_id:49147] should run infra containers on reserved CPUs", func() {
-			var cpusetPath string
-			// find used because that crictl does not show infra containers, `runc list` shows them
-			// but you will need somehow to find infra containers ID's
-			podUID := strings.Replace(string(testpod.UID), "-", "_", -1)
-			podCgroup := ""
-			if cgroupV2 {
-				cpusetPath = "/rootfs/sys/fs/cgroup/kubepods.slice"
-			} else {
-				cpusetPath = "/rootfs/sys/fs/cgroup/cpuset"
-			}
-
-			Eventually(func() string {
-				cmd := []string{"/bin/bash", "-c", fmt.Sprintf("find %s -name *%s*", cpusetPath, podUID)}
-				out, err := nodes.ExecCommand(context.TODO(), workerRTNode, cmd)
-				Expect(err).ToNot(HaveOccurred())
-				podCgroup = testutils.ToString(out)
-				return podCgroup
-			}, cluster.ComputeTestTimeout(30*time.Second, RunningOnSingleNode), 5*time.Second).ShouldNot(BeEmpty(),
-				fmt.Sprintf("cannot find cgroup for pod %q", podUID))
-
-			containersCgroups := ""
-			Eventually(func() string {
-				cmd := []string{"/bin/bash", "-c", fmt.Sprintf("find %s -name crio-*", podCgroup)}
-				out, err := nodes.ExecCommand(context.TODO(), workerRTNode, cmd)
-				Expect(err).ToNot(HaveOccurred())
-				containersCgroups = testutils.ToString(out)
-				return containersCgroups
-			}, cluster.ComputeTestTimeout(30*time.Second, RunningOnSingleNode), 5*time.Second).ShouldNot(BeEmpty(),
-				fmt.Sprintf("cannot find containers cgroups from pod cgroup %q", podCgroup))
-
-			containerID, err := pods.GetContainerIDByName(testpod, "test")
-			Expect(err).ToNot(HaveOccurred())
-
-			containersCgroups = strings.Trim(containersCgroups, "\n")
-			containersCgroupsDirs := strings.Split(containersCgroups, "\n")
-
-			for _, dir := range containersCgroupsDirs {
-				// skip application container cgroup
-				// skip conmon containers
-				if strings.Contains(dir, containerID) || strings.Contains(dir, "conmon") {
-					continue
-				}
-
-				By("Checking what CPU the infra container is using")
-				cmd := []string{"/bin/bash", "-c", fmt.Sprintf("cat %s/cpuset.cpus", dir)}
-				out, err := nodes.ExecCommand(context.TODO(), workerRTNode, cmd)
-				Expect(err).ToNot(HaveOccurred())
-				output := testutils.ToString(out)
-				cpus, err := cpuset.Parse(output)
-				Expect(err).ToNot(HaveOccurred())
+			assertReservedOnlyInfraCgroup(ctx, workerRTNode, testpod, cgroupV2, reservedCPUSet)
+		})
 
-				Expect(cpus.List()).To(Equal(reservedCPUSet.List()))
+		It("[test_id:75511] infra cgroup stays reserved-only when IRQ load balancing is globally disabled", func() {
+			if profile.Spec.GloballyDisableIrqLoadBalancing == nil || !*profile.Spec.GloballyDisableIrqLoadBalancing {
+				Skip("GloballyDisableIrqLoadBalancing is not enabled on this profile")
 			}
+			assertReservedOnlyInfraCgroup(ctx, workerRTNode, testpod, cgroupV2, reservedCPUSet)
 		})
 	})
 
@@ -612,6 +651,164 @@ var _ = Describe("[rfe_id:27363][performance] CPU Management", Ordered, func() {
 			Expect(isSMTAlignmentError(updatedPod)).To(BeTrue(), "pod %s failed for wrong reason: %q", updatedPod.Name, updatedPod.Status.Reason)
 		})
 	})
+
+	When("restricted NUMA alignment is requested", Label(string(label.Tier0)), func() {
+		var testpod *corev1.Pod
+
+		BeforeEach(func() {
+			if profile.Spec.NUMA == nil || profile.Spec.NUMA.TopologyPolicy == nil {
+				Skip("Topology Manager Policy is not configured")
+			}
+			if *profile.Spec.NUMA.TopologyPolicy != "restricted" {
+				Skip("Topology Manager Policy is not Restricted")
+			}
+		})
+
+		AfterEach(func() {
+			if testpod == nil {
+				return
+			}
+			deleteTestPod(context.TODO(), testpod)
+		})
+
+		It("[test_id:75516] should reject a pod whose CPU request cannot be satisfied on a single NUMA node", func(ctx context.Context) {
+			numaCPUs, err := getNUMANodeForCPUs(ctx, workerRTNode)
+			Expect(err).ToNot(HaveOccurred())
+			if len(numaCPUs) < 2 {
+				Skip("Requires a worker node with more than one NUMA node")
+			}
+
+			largestNUMASize := 0
+			for _, cpus := range numaCPUs {
+				if cpus.Size() > largestNUMASize {
+					largestNUMASize = cpus.Size()
+				}
+			}
+
+			cpuCount := largestNUMASize + 1
+			if cpuCount >= isolatedCPUSet.Size() {
+				Skip(fmt.Sprintf("requires %d isolated CPUs to overflow a single NUMA node, only %d available", cpuCount, isolatedCPUSet.Size()))
+			}
+
+			testpod = promotePodToGuaranteed(getStressPod(workerRTNode.Name, cpuCount))
+			testpod.Namespace = testutils.NamespaceTesting
+
+			err = testclient.DataPlaneClient.Create(ctx, testpod)
+			Expect(err).ToNot(HaveOccurred())
+
+			currentPod, err := pods.WaitForPredicate(ctx, client.ObjectKeyFromObject(testpod), 10*time.Minute, func(pod *corev1.Pod) (bool, error) {
+				if pod.Status.Phase != corev1.PodPending {
+					return true, nil
+				}
+				return false, nil
+			})
+			Expect(err).ToNot(HaveOccurred(), "expected the pod to keep pending, but its current phase is %s", currentPod.Status.Phase)
+
+			updatedPod := &corev1.Pod{}
+			err = testclient.DataPlaneClient.Get(ctx, client.ObjectKeyFromObject(testpod), updatedPod)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(updatedPod.Status.Phase).To(Equal(corev1.PodFailed), "pod %s not failed: %v", updatedPod.Name, updatedPod.Status)
+			Expect(isTopologyAffinityError(updatedPod)).To(BeTrue(), "pod %s failed for wrong reason: %q", updatedPod.Name, updatedPod.Status.Reason)
+		})
+
+		It("[test_id:75517] should admit and confine to one NUMA node a pod that fits within a single NUMA node", func(ctx context.Context) {
+			numaCPUs, err := getNUMANodeForCPUs(ctx, workerRTNode)
+			Expect(err).ToNot(HaveOccurred())
+
+			cpuCount := 2
+			if cpuCount >= isolatedCPUSet.Size() {
+				Skip(fmt.Sprintf("cpus request %d is greater than the available isolated cpus %d", cpuCount, isolatedCPUSet.Size()))
+			}
+
+			testpod = promotePodToGuaranteed(getStressPod(workerRTNode.Name, cpuCount))
+			testpod.Namespace = testutils.NamespaceTesting
+
+			err = testclient.DataPlaneClient.Create(ctx, testpod)
+			Expect(err).ToNot(HaveOccurred())
+			testpod, err = pods.WaitForCondition(ctx, client.ObjectKeyFromObject(testpod), corev1.PodReady, corev1.ConditionTrue, 10*time.Minute)
+			logEventsForPod(testpod)
+			Expect(err).ToNot(HaveOccurred())
+
+			assignedCpus, err := getPodCpus(testpod)
+			Expect(err).ToNot(HaveOccurred())
+			assignedCPUSet, err := cpuset.Parse(assignedCpus)
+			Expect(err).ToNot(HaveOccurred())
+
+			By("checking the assigned CPUs are confined to a single NUMA node")
+			confined := false
+			for _, cpus := range numaCPUs {
+				if assignedCPUSet.IsSubsetOf(cpus) {
+					confined = true
+					break
+				}
+			}
+			Expect(confined).To(BeTrue(), "assigned CPUs %s span more than one NUMA node: %v", assignedCPUSet, numaCPUs)
+		})
+	})
+
+	When("best-effort NUMA alignment is requested", Label(string(label.Tier0)), func() {
+		var testpod *corev1.Pod
+
+		BeforeEach(func() {
+			if profile.Spec.NUMA == nil || profile.Spec.NUMA.TopologyPolicy == nil {
+				Skip("Topology Manager Policy is not configured")
+			}
+			if *profile.Spec.NUMA.TopologyPolicy != "best-effort" {
+				Skip("Topology Manager Policy is not Best-Effort")
+			}
+		})
+
+		AfterEach(func() {
+			if testpod == nil {
+				return
+			}
+			deleteTestPod(context.TODO(), testpod)
+		})
+
+		It("[test_id:75518] admission always succeeds regardless of NUMA alignment", func(ctx context.Context) {
+			numaCPUs, err := getNUMANodeForCPUs(ctx, workerRTNode)
+			Expect(err).ToNot(HaveOccurred())
+
+			largestNUMASize := 0
+			for _, cpus := range numaCPUs {
+				if cpus.Size() > largestNUMASize {
+					largestNUMASize = cpus.Size()
+				}
+			}
+
+			cpuCount := largestNUMASize + 1
+			if len(numaCPUs) < 2 || cpuCount >= isolatedCPUSet.Size() {
+				cpuCount = 2
+			}
+			if cpuCount >= isolatedCPUSet.Size() {
+				Skip(fmt.Sprintf("cpus request %d is greater than the available isolated cpus %d", cpuCount, isolatedCPUSet.Size()))
+			}
+
+			testpod = promotePodToGuaranteed(getStressPod(workerRTNode.Name, cpuCount))
+			testpod.Namespace = testutils.NamespaceTesting
+
+			err = testclient.DataPlaneClient.Create(ctx, testpod)
+			Expect(err).ToNot(HaveOccurred())
+			testpod, err = pods.WaitForCondition(ctx, client.ObjectKeyFromObject(testpod), corev1.PodReady, corev1.ConditionTrue, 10*time.Minute)
+			logEventsForPod(testpod)
+			Expect(err).ToNot(HaveOccurred(), "best-effort policy must admit the pod even when it cannot fit on a single NUMA node")
+
+			assignedCpus, err := getPodCpus(testpod)
+			Expect(err).ToNot(HaveOccurred())
+			assignedCPUSet, err := cpuset.Parse(assignedCpus)
+			Expect(err).ToNot(HaveOccurred())
+
+			aligned := false
+			for _, cpus := range numaCPUs {
+				if assignedCPUSet.IsSubsetOf(cpus) {
+					aligned = true
+					break
+				}
+			}
+			testlog.Infof("best-effort pod %s NUMA-aligned: %v (assigned CPUs %s)", testpod.Name, aligned, assignedCPUSet)
+		})
+	})
 	Describe("Hyper-thread aware scheduling for guaranteed pods", Label(string(label.Tier1)), func() {
 		var testpod *corev1.Pod
 
@@ -685,6 +882,141 @@ var _ = Describe("[rfe_id:27363][performance] CPU Management", Ordered, func() {
 			Entry("[test_id:46539] HT aware scheduling on SNO cluster and Workload Partitioning enabled", context.TODO(), false, true, true),
 		)
 
+		It("[test_id:75521] a higher-priority guaranteed pod preempts a lower-priority one without an exclusive-cpuset overlap window", func(ctx context.Context) {
+			if !cgroupV2 {
+				Skip("cpuset.cpus.exclusive is part of cgroupv2 interfaces")
+			}
+			cpuID := onlineCPUSet.UnsortedList()[0]
+			if nodes.GetSMTLevel(ctx, cpuID, workerRTNode) < 2 {
+				Skip(fmt.Sprintf("designated worker node %q does not have SMT enabled", workerRTNode.Name))
+			}
+			if isolatedCPUSet.Size() < 4 {
+				Skip(fmt.Sprintf("requires at least 4 isolated CPUs to leave no free capacity for the preempting pod, only %d available", isolatedCPUSet.Size()))
+			}
+
+			By("creating a PriorityClass for the preempting pod")
+			highPriorityClass := &schedulingv1.PriorityClass{
+				ObjectMeta:    metav1.ObjectMeta{Name: "chunk3-5-preemptor"},
+				Value:         1000000,
+				GlobalDefault: false,
+				Description:   "Used by [test_id:75521] to preempt a low-priority guaranteed pod",
+			}
+			Expect(testclient.DataPlaneClient.Create(ctx, highPriorityClass)).ToNot(HaveOccurred())
+			defer func() {
+				_ = testclient.DataPlaneClient.Delete(ctx, highPriorityClass)
+			}()
+
+			By("creating a low-priority guaranteed pod pinned to a pair of SMT siblings")
+			lowPod := startHTtestPod(ctx, 2)
+			lowCpus, err := getPodCpus(lowPod)
+			Expect(err).ToNot(HaveOccurred())
+			lowCPUSet, err := cpuset.Parse(lowCpus)
+			Expect(err).ToNot(HaveOccurred())
+
+			By("consuming the remaining isolated capacity so the preempting pod has no free CPUs to land on")
+			fillerCount := (isolatedCPUSet.Size() - 2) / 2
+			fillerPods := make([]*corev1.Pod, 0, fillerCount)
+			for i := 0; i < fillerCount; i++ {
+				fillerPod := promotePodToGuaranteed(getStressPod(workerRTNode.Name, 2))
+				fillerPod.Namespace = testutils.NamespaceTesting
+				fillerPod.GenerateName = fmt.Sprintf("chunk3-5-filler-%d-", i)
+				Expect(testclient.DataPlaneClient.Create(ctx, fillerPod)).ToNot(HaveOccurred())
+				fillerPod, err = pods.WaitForCondition(ctx, client.ObjectKeyFromObject(fillerPod), corev1.PodReady, corev1.ConditionTrue, 10*time.Minute)
+				Expect(err).ToNot(HaveOccurred())
+				fillerPods = append(fillerPods, fillerPod)
+			}
+			defer func() {
+				for _, fillerPod := range fillerPods {
+					deleteTestPod(ctx, fillerPod)
+				}
+			}()
+
+			kubepodsExclusiveCpus := fmt.Sprintf("%s/kubepods.slice/cpuset.cpus.exclusive", cgroupRoot)
+			type exclusiveSample struct {
+				at   time.Time
+				cpus cpuset.CPUSet
+			}
+			var samples []exclusiveSample
+			stopPolling := make(chan struct{})
+			pollingDone := make(chan struct{})
+			go func() {
+				defer close(pollingDone)
+				ticker := time.NewTicker(100 * time.Millisecond)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-stopPolling:
+						return
+					case <-ticker.C:
+						out, err := nodes.ExecCommand(ctx, workerRTNode, []string{"cat", kubepodsExclusiveCpus})
+						if err != nil {
+							continue
+						}
+						cpus, err := cpuset.Parse(strings.TrimSpace(testutils.ToString(out)))
+						if err != nil {
+							continue
+						}
+						samples = append(samples, exclusiveSample{at: time.Now(), cpus: cpus})
+					}
+				}
+			}()
+
+			By("creating a higher-priority guaranteed pod that can only be satisfied by preempting the low-priority pod")
+			highPod := promotePodToGuaranteed(getStressPod(workerRTNode.Name, 2))
+			highPod.Namespace = testutils.NamespaceTesting
+			highPod.Spec.PriorityClassName = highPriorityClass.Name
+			Expect(testclient.DataPlaneClient.Create(ctx, highPod)).ToNot(HaveOccurred())
+			highPod, err = pods.WaitForCondition(ctx, client.ObjectKeyFromObject(highPod), corev1.PodReady, corev1.ConditionTrue, 10*time.Minute)
+			Expect(err).ToNot(HaveOccurred())
+			defer deleteTestPod(ctx, highPod)
+
+			close(stopPolling)
+			<-pollingDone
+
+			By("checking the low-priority pod was preempted")
+			Eventually(func() bool {
+				updated := &corev1.Pod{}
+				err := testclient.DataPlaneClient.Get(ctx, client.ObjectKeyFromObject(lowPod), updated)
+				if errors.IsNotFound(err) {
+					return true
+				}
+				Expect(err).ToNot(HaveOccurred())
+				return updated.DeletionTimestamp != nil
+			}).WithTimeout(2*time.Minute).WithPolling(2*time.Second).Should(BeTrue(), "low-priority pod was not preempted")
+
+			By("checking the high-priority pod's cpuset maps to complete SMT sibling pairs")
+			Expect(checkPodHTSiblings(ctx, highPod)).To(BeTrue(), "high-priority pod cpu set does not map to host cpu sibling pairs")
+
+			highCpus, err := getPodCpus(highPod)
+			Expect(err).ToNot(HaveOccurred())
+			highCPUSet, err := cpuset.Parse(highCpus)
+			Expect(err).ToNot(HaveOccurred())
+
+			By("checking no polled cpuset.cpus.exclusive sample straddled both pods' CPUs")
+			for _, s := range samples {
+				straddles := !s.cpus.Intersection(lowCPUSet).IsEmpty() && !s.cpus.Intersection(highCPUSet).IsEmpty()
+				Expect(straddles).To(BeFalse(), "cpuset.cpus.exclusive at %v contained both the evicted pod's CPUs (%s) and the new pod's CPUs (%s): %s", s.at, lowCPUSet, highCPUSet, s.cpus)
+			}
+
+			By("checking cpuset.cpus.exclusive ends up containing exactly the new pod's CPUs")
+			Eventually(func() (cpuset.CPUSet, error) {
+				out, err := nodes.ExecCommand(ctx, workerRTNode, []string{"cat", kubepodsExclusiveCpus})
+				if err != nil {
+					return cpuset.CPUSet{}, err
+				}
+				return cpuset.Parse(strings.TrimSpace(testutils.ToString(out)))
+			}).WithTimeout(2 * time.Minute).WithPolling(5 * time.Second).Should(Equal(highCPUSet))
+		})
+
+		// Verifying that unrelated guaranteed pods on SMT sibling threads get
+		// distinct SCHED_CORE cookies needs a way to read a container's
+		// kernel-assigned core-scheduling cookie from the node. There is no
+		// /proc file exposing it; reading it back requires a
+		// prctl(PR_SCHED_CORE_GET) call made from inside the target's PID
+		// namespace, which is more than a plain node_inspector exec/chroot
+		// command can do without a small helper binary shipped in the node
+		// image - which is not part of this checkout. No core-scheduling
+		// coverage is added here pending that helper landing.
 	})
 	// Automates OCPBUGS-34812: cgroupsv2: failed to write on cpuset.cpus.exclusive
 	Context("Cgroupsv2", func() {
@@ -801,11 +1133,97 @@ var _ = Describe("[rfe_id:27363][performance] CPU Management", Ordered, func() {
 			}
 		})
 	})
+	// Hardens the pre-start-hook path OCPBUGS-34812 exposed: kubelet's own
+	// PostStart/PreStop exec hooks must only ever observe the container's
+	// already-assigned cpuset, never a stale or shared kubepods cpuset.
+	Context("Lifecycle Hooks", Label(string(label.Tier1)), func() {
+		It("[test_id:75519] cpuset observed by postStart matches the container's final cpuset", func(ctx context.Context) {
+			cpuCount := 2
+			if cpuCount >= isolatedCPUSet.Size() {
+				Skip(fmt.Sprintf("cpus request %d is greater than the available isolated cpus %d", cpuCount, isolatedCPUSet.Size()))
+			}
+
+			testpod := getLifecycleHookPod(workerRTNode.Name, cpuCount)
+			testpod.Namespace = testutils.NamespaceTesting
+
+			err := testclient.DataPlaneClient.Create(ctx, testpod)
+			Expect(err).ToNot(HaveOccurred())
+			defer deleteTestPod(ctx, testpod)
+
+			testpod, err = pods.WaitForCondition(ctx, client.ObjectKeyFromObject(testpod), corev1.PodReady, corev1.ConditionTrue, 10*time.Minute)
+			logEventsForPod(testpod)
+			Expect(err).ToNot(HaveOccurred())
+
+			By("reading the cpuset the postStart hook observed")
+			postStartCPUSet, err := readHookCpuset(testpod, "/shared/poststart.cpus")
+			Expect(err).ToNot(HaveOccurred())
+
+			By("reading the container's final cpuset")
+			finalCpus, err := getPodCpus(testpod)
+			Expect(err).ToNot(HaveOccurred())
+			finalCPUSet, err := cpuset.Parse(finalCpus)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(postStartCPUSet).To(Equal(finalCPUSet),
+				"postStart hook observed cpuset %s, final container cpuset is %s", postStartCPUSet, finalCPUSet)
+		})
+
+		It("[test_id:75520] no hook invocation observes another guaranteed pod's CPUs under rapid churn", func(ctx context.Context) {
+			cpuCount := 2
+			if 2*cpuCount >= isolatedCPUSet.Size() {
+				Skip(fmt.Sprintf("requires %d isolated CPUs for two concurrent pods, only %d available", 2*cpuCount, isolatedCPUSet.Size()))
+			}
+
+			for i := 0; i < 5; i++ {
+				testlog.Infof("iteration %d: creating two guaranteed pods with lifecycle hooks", i)
+
+				podA := getLifecycleHookPod(workerRTNode.Name, cpuCount)
+				podA.Namespace = testutils.NamespaceTesting
+				podB := getLifecycleHookPod(workerRTNode.Name, cpuCount)
+				podB.Namespace = testutils.NamespaceTesting
+
+				Expect(testclient.DataPlaneClient.Create(ctx, podA)).ToNot(HaveOccurred())
+				Expect(testclient.DataPlaneClient.Create(ctx, podB)).ToNot(HaveOccurred())
+
+				var err error
+				podA, err = pods.WaitForCondition(ctx, client.ObjectKeyFromObject(podA), corev1.PodReady, corev1.ConditionTrue, 10*time.Minute)
+				Expect(err).ToNot(HaveOccurred())
+				podB, err = pods.WaitForCondition(ctx, client.ObjectKeyFromObject(podB), corev1.PodReady, corev1.ConditionTrue, 10*time.Minute)
+				Expect(err).ToNot(HaveOccurred())
+
+				aCpus, err := getPodCpus(podA)
+				Expect(err).ToNot(HaveOccurred())
+				aCPUSet, err := cpuset.Parse(aCpus)
+				Expect(err).ToNot(HaveOccurred())
+
+				bCpus, err := getPodCpus(podB)
+				Expect(err).ToNot(HaveOccurred())
+				bCPUSet, err := cpuset.Parse(bCpus)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(aCPUSet.Intersection(bCPUSet).IsEmpty()).To(BeTrue(), "guaranteed pods were assigned overlapping cpusets: %s vs %s", aCPUSet, bCPUSet)
+
+				aHookCPUSet, err := readHookCpuset(podA, "/shared/poststart.cpus")
+				Expect(err).ToNot(HaveOccurred())
+				bHookCPUSet, err := readHookCpuset(podB, "/shared/poststart.cpus")
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(aHookCPUSet.Intersection(bCPUSet).IsEmpty()).To(BeTrue(),
+					"pod A's postStart hook observed CPUs %s overlapping pod B's cpuset %s", aHookCPUSet, bCPUSet)
+				Expect(bHookCPUSet.Intersection(aCPUSet).IsEmpty()).To(BeTrue(),
+					"pod B's postStart hook observed CPUs %s overlapping pod A's cpuset %s", bHookCPUSet, aCPUSet)
+
+				deleteTestPod(ctx, podA)
+				deleteTestPod(ctx, podB)
+			}
+		})
+	})
 	Context("Crio Annotations", Label(string(label.Tier0)), func() {
 		var testpod *corev1.Pod
 		var allTestpods map[types.UID]*corev1.Pod
 		var busyCpusImage string
 		var targetNode = &corev1.Node{}
+		var baselineDomains domainSnapshot
 		annotations := map[string]string{
 			"cpu-load-balancing.crio.io": "disable",
 			"cpu-quota.crio.io":          "disable",
@@ -844,6 +1262,9 @@ var _ = Describe("[rfe_id:27363][performance] CPU Management", Ordered, func() {
 				Expect(defaultCpuNotInSchedulingDomains).To(BeEmpty(), "the test expects all CPUs within a scheduling domain when starting")
 			}
 
+			baselineDomains, err = getDomainSnapshot(ctx, workerRTNode)
+			Expect(err).ToNot(HaveOccurred(), "failed to snapshot the scheduling domains before starting the pod")
+
 			By("Starting the pod")
 			testpod.Spec.NodeSelector = testutils.NodeSelectorLabels
 			runtimeClass := components.GetComponentName(profile.Name, components.ComponentNamePrefix)
@@ -933,6 +1354,29 @@ var _ = Describe("[rfe_id:27363][performance] CPU Management", Ordered, func() {
 				Expect(err).ToNot(HaveOccurred(), "unable to parse cpuset.cpus.exclusive")
 				Expect(podCpuset.Equals(exclusiveCpuset)).To(BeTrue())
 			})
+
+			It("[test_id:75526] pod CPUs are excluded from every scheduling-domain level, not only the top one", func(ctx context.Context) {
+				output, err := getPodCpus(testpod)
+				Expect(err).ToNot(HaveOccurred(), "unable to fetch cpus used by testpod")
+				podCpus, err := cpuset.Parse(output)
+				Expect(err).ToNot(HaveOccurred(), "unable to parse cpuset used by pod")
+
+				By("checking no scheduling-domain level still spans the pod's isolated CPUs")
+				snapshot, err := getDomainSnapshot(ctx, targetNode)
+				Expect(err).ToNot(HaveOccurred(), "unable to read the scheduling domain hierarchy")
+				assertDomainSpanExcludes(snapshot, podCpus)
+
+				By("checking no domain level merges CPUs across NUMA nodes")
+				numaCPUs, err := getNUMANodeForCPUs(ctx, targetNode)
+				Expect(err).ToNot(HaveOccurred(), "unable to read the node's NUMA topology")
+				assertNoDomainCrossesNUMA(snapshot, numaCPUs)
+
+				By("checking the pod's CPUs actually moved the domain hierarchy from its pristine baseline")
+				diff := diffDomains(baselineDomains, snapshot)
+				testlog.Infof("scheduling-domain changes since the pristine baseline:\n%s", diff)
+				Expect(diff).ToNot(BeEmpty(),
+					"no scheduling-domain level changed since the pristine baseline even though %s should have been excluded from load balancing", podCpus)
+			})
 		})
 
 		Describe("CPU Quota annotation", func() {
@@ -954,6 +1398,16 @@ var _ = Describe("[rfe_id:27363][performance] CPU Management", Ordered, func() {
 				Expect(cpuCfg.Stat["nr_throttled"]).To(Equal("0"), "cpu throttling not disabled on pod=%q, container=%q", client.ObjectKeyFromObject(testpod), testpod.Spec.Containers[0].Name)
 			})
 		})
+
+		// This suite has no coverage for advertising profile.Spec.CPU.Isolated
+		// as a countable extended resource (e.g. openshift.io/isolcpus) so
+		// burstable/best-effort pods can pin to isolated CPUs without going
+		// through the static CPU manager.
+		//
+		// Nor does it cover a Spec.CPU.IsolatedAsDevices-gated variant of the
+		// same device-plugin model (with the allocation contract communicated
+		// via a CRI-O annotation written from the plugin's Allocate response
+		// instead of an env var).
 	})
 
 	Context("Check container runtimes cpu usage", Label(string(label.OpenShift)), func() {
@@ -1063,6 +1517,271 @@ var _ = Describe("[rfe_id:27363][performance] CPU Management", Ordered, func() {
 		})
 	})
 
+	// The admission-time mutator that populates "cpulimit" in the
+	// target.workload.openshift.io/management annotation from
+	// resources.limits.cpu, and zeroes the container's own limit so kubelet
+	// doesn't double-account, lives in the management-workload admission
+	// webhook, which is not part of this checkout. This Context constructs
+	// the annotation CRI-O is expected to receive directly, to pin down the
+	// CFS-quota contract on the CRI-O/runc side.
+	Context("Workload Partitioning CPU limit annotation", Label(string(label.Tier1)), func() {
+		var testpod *corev1.Pod
+
+		BeforeEach(func() {
+			if !checkForWorkloadPartitioning(context.TODO()) {
+				Skip("Workload Partitioning is not enabled on this cluster")
+			}
+		})
+
+		AfterEach(func() {
+			if testpod != nil {
+				deleteTestPod(context.TODO(), testpod)
+			}
+		})
+
+		It("[test_id:75505] CFS quota of a management pod matches its cpulimit annotation", func(ctx context.Context) {
+			const cpuLimitMillicores = 500
+			const cfsPeriodUs = 100000
+
+			testpod = pods.GetTestPod()
+			testpod.Namespace = testutils.NamespaceTesting
+			testpod.Spec.NodeSelector = map[string]string{testutils.LabelHostname: workerRTNode.Name}
+			testpod.Annotations = map[string]string{
+				"target.workload.openshift.io/management": fmt.Sprintf(`{"cpushares": 2, "cpulimit": %d}`, cpuLimitMillicores),
+			}
+			runtimeClass := components.GetComponentName(profile.Name, components.ComponentNamePrefix)
+			testpod.Spec.RuntimeClassName = &runtimeClass
+
+			err := testclient.DataPlaneClient.Create(ctx, testpod)
+			Expect(err).ToNot(HaveOccurred())
+			testpod, err = pods.WaitForCondition(ctx, client.ObjectKeyFromObject(testpod), corev1.PodReady, corev1.ConditionTrue, 10*time.Minute)
+			logEventsForPod(testpod)
+			Expect(err).ToNot(HaveOccurred())
+
+			By("checking the pod's container runs pinned within the reserved CPU pool")
+			output, err := getPodCpus(testpod)
+			Expect(err).ToNot(HaveOccurred(), "unable to fetch cpus used by testpod")
+			podCpus, err := cpuset.Parse(output)
+			Expect(err).ToNot(HaveOccurred(), "unable to parse cpuset used by pod")
+			Expect(podCpus.IsSubsetOf(reservedCPUSet)).To(BeTrue(), "management pod CPUs (%s) are not within the reserved set (%s)", podCpus, reservedCPUSet)
+
+			By("checking the CFS quota derived from the cpulimit annotation")
+			expectedQuota := cpuLimitMillicores * cfsPeriodUs / 1000
+			cpuCfg := &controller.Cpu{}
+			err = getter.Container(ctx, testpod, testpod.Spec.Containers[0].Name, cpuCfg)
+			Expect(err).ToNot(HaveOccurred())
+			if cgroupV2 {
+				fields := strings.Fields(cpuCfg.Quota)
+				Expect(fields).ToNot(BeEmpty())
+				Expect(fields[0]).To(Equal(strconv.Itoa(expectedQuota)), "cpu.max quota does not match cpulimit*period")
+			} else {
+				Expect(cpuCfg.Quota).To(Equal(strconv.Itoa(expectedQuota)), "cpu.cfs_quota_us does not match cpulimit*period")
+			}
+		})
+
+		It("[test_id:75522] CRI-O's per-container resources.workload.openshift.io annotation mirrors the cpulimit annotation", func(ctx context.Context) {
+			const cpuShares = 2
+			const cpuLimitMillicores = 500
+			const cfsPeriodUs = 100000
+
+			testpod = pods.GetTestPod()
+			testpod.Namespace = testutils.NamespaceTesting
+			testpod.Spec.NodeSelector = map[string]string{testutils.LabelHostname: workerRTNode.Name}
+			testpod.Annotations = map[string]string{
+				"target.workload.openshift.io/management": fmt.Sprintf(`{"cpushares": %d, "cpulimit": %d}`, cpuShares, cpuLimitMillicores),
+			}
+			runtimeClass := components.GetComponentName(profile.Name, components.ComponentNamePrefix)
+			testpod.Spec.RuntimeClassName = &runtimeClass
+
+			err := testclient.DataPlaneClient.Create(ctx, testpod)
+			Expect(err).ToNot(HaveOccurred())
+			testpod, err = pods.WaitForCondition(ctx, client.ObjectKeyFromObject(testpod), corev1.PodReady, corev1.ConditionTrue, 10*time.Minute)
+			logEventsForPod(testpod)
+			Expect(err).ToNot(HaveOccurred())
+
+			By("checking the per-container resources.workload.openshift.io annotation carries cpushares and cpulimit through unchanged")
+			containerName := testpod.Spec.Containers[0].Name
+			configs := getConfigJsonInfo(testpod, containerName, workerRTNode)
+			Expect(configs).ToNot(BeEmpty(), "no config.json found for pod %s", testpod.Name)
+			workload, err := configs[0].getWorkloadResourceAnnotation(containerName)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(workload.CPUShares).To(Equal(cpuShares), "resources.workload.openshift.io cpushares does not match the management annotation")
+			Expect(workload.CPULimit).To(Equal(cpuLimitMillicores), "resources.workload.openshift.io cpulimit does not match the management annotation")
+
+			By("checking CRI-O produced the matching cfs quota under the container's cgroup")
+			expectedQuota := cpuLimitMillicores * cfsPeriodUs / 1000
+			cpuCfg := &controller.Cpu{}
+			err = getter.Container(ctx, testpod, containerName, cpuCfg)
+			Expect(err).ToNot(HaveOccurred())
+			if cgroupV2 {
+				fields := strings.Fields(cpuCfg.Quota)
+				Expect(fields).ToNot(BeEmpty())
+				Expect(fields[0]).To(Equal(strconv.Itoa(expectedQuota)), "cpu.max quota does not match the resources.workload.openshift.io cpulimit")
+			} else {
+				Expect(cpuCfg.Quota).To(Equal(strconv.Itoa(expectedQuota)), "cpu.cfs_quota_us does not match the resources.workload.openshift.io cpulimit")
+			}
+		})
+
+		It("[test_id:75506] static pod manifests carry the cpulimit annotation through to CRI-O unchanged", func(ctx context.Context) {
+			const manifestPath = "/etc/kubernetes/manifests/cnf-chunk2-3-static-pod.yaml"
+			const staticPodName = "cnf-chunk2-3-static-pod"
+
+			manifest := fmt.Sprintf(`apiVersion: v1
+kind: Pod
+metadata:
+  name: %s
+  namespace: %s
+  annotations:
+    target.workload.openshift.io/management: '{"cpushares": 2, "cpulimit": 500}'
+spec:
+  nodeName: %s
+  containers:
+  - name: test
+    image: %s
+    command: ["/bin/sh", "-c", "sleep infinity"]
+`, staticPodName, testutils.NamespaceTesting, workerRTNode.Name, images.Test())
+
+			By("writing a static pod manifest with the cpulimit annotation to the node's manifest directory")
+			writeManifest := []string{"/bin/bash", "-c", fmt.Sprintf("cat > %s <<'EOF'\n%s\nEOF", manifestPath, manifest)}
+			_, err := nodes.ExecCommand(ctx, workerRTNode, writeManifest)
+			Expect(err).ToNot(HaveOccurred(), "failed to write static pod manifest")
+
+			defer func() {
+				removeManifest := []string{"/bin/bash", "-c", fmt.Sprintf("rm -f %s", manifestPath)}
+				_, _ = nodes.ExecCommand(ctx, workerRTNode, removeManifest)
+			}()
+
+			mirrorPodName := fmt.Sprintf("%s-%s", staticPodName, workerRTNode.Name)
+			var mirrorPod *corev1.Pod
+			Eventually(func() error {
+				mirrorPod = &corev1.Pod{}
+				return testclient.DataPlaneClient.Get(ctx, client.ObjectKey{Namespace: testutils.NamespaceTesting, Name: mirrorPodName}, mirrorPod)
+			}).WithTimeout(2*time.Minute).WithPolling(5*time.Second).Should(Succeed(), "mirror pod for static pod manifest did not appear")
+
+			By("checking the static pod's config.json carries the cpulimit annotation through unchanged")
+			configs := getConfigJsonInfo(mirrorPod, "test", workerRTNode)
+			Expect(configs).ToNot(BeEmpty(), "no config.json found for static pod %s", mirrorPodName)
+			Expect(configs[0].Annotations.Workload).To(Equal(`{"cpushares": 2, "cpulimit": 500}`),
+				"static pod's management annotation was not copied through to CRI-O unchanged")
+		})
+	})
+
+	// Serial startHTtestPod/deleteTestPod loops are too slow to stress the
+	// allocator at any real scale, and a slow serial loop is also unlikely to
+	// surface the races (duplicated CPU assignments, leaked cpusets) that a
+	// burst of concurrent requests can.
+	Context("Parallel pod lifecycle soak test", Label(string(label.Tier1)), func() {
+		It("[test_id:75525] cpumanager hands out disjoint cpusets under concurrent pod churn across worker-RT nodes", func(ctx context.Context) {
+			workerRTNodes, err := nodes.GetByLabels(testutils.NodeSelectorLabels)
+			Expect(err).ToNot(HaveOccurred())
+			workerRTNodes, err = nodes.MatchingOptionalSelector(workerRTNodes)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(workerRTNodes).ToNot(BeEmpty())
+
+			const perPodCPUs = 2
+			podCount := 24
+			concurrency := 8
+
+			// pods are handed out round-robin across workerRTNodes and all stay
+			// alive until teardown, so the worst case is ceil(podCount/len(workerRTNodes))
+			// pods concurrently held on the same node; cap podCount so that never
+			// reaches a node's isolated capacity.
+			maxPodsPerNode := isolatedCPUSet.Size()/perPodCPUs - 1
+			if maxPodsPerNode < 1 {
+				Skip(fmt.Sprintf("requires at least %d isolated CPUs per worker-RT node to run two concurrent %d-cpu pods, only %d available", 2*perPodCPUs, perPodCPUs, isolatedCPUSet.Size()))
+			}
+			if maxPods := maxPodsPerNode * len(workerRTNodes); podCount > maxPods {
+				podCount = maxPods
+			}
+			if concurrency > podCount {
+				concurrency = podCount
+			}
+
+			results := make([]podLifecycleResult, podCount)
+			for i := range results {
+				results[i].Index = i
+			}
+			createJobs := make([]parallel.Job, podCount)
+			for i := 0; i < podCount; i++ {
+				i := i
+				createJobs[i] = func() error {
+					defer GinkgoRecover()
+					node := workerRTNodes[i%len(workerRTNodes)]
+					pod := promotePodToGuaranteed(getStressPod(node.Name, perPodCPUs))
+					pod.Namespace = testutils.NamespaceTesting
+					pod.GenerateName = fmt.Sprintf("soak-pod-%d-", i)
+
+					if err := testclient.DataPlaneClient.Create(ctx, pod); err != nil {
+						results[i].Err = err
+						return err
+					}
+					pod, err := pods.WaitForCondition(ctx, client.ObjectKeyFromObject(pod), corev1.PodReady, corev1.ConditionTrue, 10*time.Minute)
+					results[i].Pod = pod
+					if err != nil {
+						results[i].Err = err
+						results[i].SMTAlignmentError = isSMTAlignmentError(pod)
+						return err
+					}
+
+					cpus, err := getPodCpus(pod)
+					if err != nil {
+						results[i].Err = err
+						return err
+					}
+					cpuSet, err := cpuset.Parse(cpus)
+					if err != nil {
+						results[i].Err = err
+						return err
+					}
+					results[i].Node = pod.Spec.NodeName
+					results[i].UID = pod.UID
+					results[i].CPUs = cpuSet
+					return nil
+				}
+			}
+
+			By(fmt.Sprintf("creating %d guaranteed pods with %d-way concurrency", podCount, concurrency))
+			parallel.ParallelExecute(createJobs, concurrency)
+
+			defer func() {
+				deleteJobs := make([]parallel.Job, 0, podCount)
+				for i := range results {
+					pod := results[i].Pod
+					if pod == nil {
+						continue
+					}
+					deleteJobs = append(deleteJobs, func() error {
+						defer GinkgoRecover()
+						deleteTestPod(ctx, pod)
+						return nil
+					})
+				}
+				By(fmt.Sprintf("tearing down %d pods with %d-way concurrency", len(deleteJobs), concurrency))
+				parallel.ParallelExecute(deleteJobs, concurrency)
+			}()
+
+			By("checking every pod was scheduled without an SMT-alignment rejection")
+			for _, r := range results {
+				Expect(r.SMTAlignmentError).To(BeFalse(), "pod %q was rejected with an SMT-alignment error during the soak", r.podName())
+				Expect(r.Err).ToNot(HaveOccurred(), "pod %q failed during the soak: %v", r.podName(), r.Err)
+			}
+
+			By("checking no two pods on the same node were handed overlapping CPUs")
+			perNode := map[string][]podLifecycleResult{}
+			for _, r := range results {
+				perNode[r.Node] = append(perNode[r.Node], r)
+			}
+			for node, nodeResults := range perNode {
+				for a := 0; a < len(nodeResults); a++ {
+					for b := a + 1; b < len(nodeResults); b++ {
+						overlap := nodeResults[a].CPUs.Intersection(nodeResults[b].CPUs)
+						Expect(overlap.IsEmpty()).To(BeTrue(),
+							"pods %q and %q on node %q were both handed overlapping CPUs %s", nodeResults[a].podName(), nodeResults[b].podName(), node, overlap)
+					}
+				}
+			}
+		})
+	})
 })
 
 func extractConfigInfo(output string) (*ContainerConfig, error) {
@@ -1072,9 +1791,25 @@ func extractConfigInfo(output string) (*ContainerConfig, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config.json: %v", err)
 	}
+
+	var raw struct {
+		Annotations map[string]string `json:"annotations"`
+	}
+	if err := json.Unmarshal([]byte(output), &raw); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config.json annotations: %v", err)
+	}
+	config.rawAnnotations = raw.Annotations
+
 	return &config, nil
 }
 
+// getConfigJsonInfo reads the CRI-O config.json CRI-O wrote for containerName
+// in pod's sandbox directly off the node. Unlike checkPodHTSiblings/getPodCpus
+// above, this deliberately does not go through the PodResources API: callers
+// need the raw OCI annotations (the workload-partitioning management
+// annotation and its cpushares/cpulimit payload), Hostname and Process.Args,
+// none of which the PodResources gRPC API exposes - it only reports CPU,
+// device and NUMA allocation, not arbitrary container config/annotations.
 func getConfigJsonInfo(pod *corev1.Pod, containerName string, workerRTNode *corev1.Node) []*ContainerConfig {
 	var pods []*ContainerConfig
 	path := "/rootfs/var/lib/containers/storage/overlay-containers/"
@@ -1151,25 +1886,20 @@ func checkForWorkloadPartitioning(ctx context.Context) bool {
 
 func checkPodHTSiblings(ctx context.Context, testpod *corev1.Pod) bool {
 	By("Get test pod CPU list")
-	containerID, err := pods.GetContainerIDByName(testpod, "test")
-	Expect(err).ToNot(HaveOccurred(), "Unable to get pod containerID")
-
-	cmd := []string{
-		"chroot",
-		"/rootfs",
-		"/bin/bash",
-		"-c",
-		fmt.Sprintf("/bin/crictl inspect %s | /bin/jq -r '.info.runtimeSpec.linux.resources.cpu.cpus'", containerID),
-	}
+	Expect(testpod.Spec.NodeName).ToNot(BeEmpty(), "testpod %s/%s still pending - no nodeName set", testpod.Namespace, testpod.Name)
 	node, err := nodes.GetByName(testpod.Spec.NodeName)
 	Expect(err).ToNot(HaveOccurred(), "failed to get node %q", testpod.Spec.NodeName)
-	Expect(testpod.Spec.NodeName).ToNot(BeEmpty(), "testpod %s/%s still pending - no nodeName set", testpod.Namespace, testpod.Name)
-	out, err := nodes.ExecCommand(ctx, node, cmd)
-	Expect(err).ToNot(HaveOccurred(), "Unable to crictl inspect containerID %q", containerID)
-	output := testutils.ToString(out)
-	podcpus, err := cpuset.Parse(strings.Trim(output, "\n"))
-	Expect(err).ToNot(
-		HaveOccurred(), "Unable to cpuset.Parse pod allocated cpu set from output %s", output)
+
+	podResources, err := podresources.List(ctx, node)
+	Expect(err).ToNot(HaveOccurred(), "Unable to query the pod-resources API on %q", node.Name)
+	containerResources, found := podresources.Lookup(podResources, testpod.Namespace, testpod.Name, "test")
+	Expect(found).To(BeTrue(), "pod-resources API has no entry for %s/%s container \"test\"", testpod.Namespace, testpod.Name)
+	podcpus := cpuset.New(containerResources.CPUIDs...)
+	var (
+		cmd    []string
+		out    []byte
+		output string
+	)
 	testlog.Infof("Test pod CPU list: %s", podcpus.String())
 
 	// aggregate cpu sibling paris from the host based on the cpus allocated to the pod
@@ -1233,6 +1963,43 @@ func isSMTAlignmentError(pod *corev1.Pod) bool {
 	return re.MatchString(pod.Status.Reason)
 }
 
+func isTopologyAffinityError(pod *corev1.Pod) bool {
+	re := regexp.MustCompile(`Topology.*Affinity.*Error`)
+	return re.MatchString(pod.Status.Reason)
+}
+
+// getNUMANodeForCPUs maps each NUMA node ID on workerRTNode to the set of
+// CPUs local to it, read from /sys/devices/system/node/node*/cpulist.
+func getNUMANodeForCPUs(ctx context.Context, workerRTNode *corev1.Node) (map[int]cpuset.CPUSet, error) {
+	cmd := []string{"/bin/bash", "-c", "for n in /sys/devices/system/node/node[0-9]*; do echo \"$(basename $n| tr -d 'node') $(cat $n/cpulist)\"; done"}
+	out, err := nodes.ExecCommand(ctx, workerRTNode, cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read NUMA node cpulists: %w", err)
+	}
+
+	numaCPUs := map[int]cpuset.CPUSet{}
+	for _, line := range strings.Split(strings.TrimSpace(testutils.ToString(out)), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("unexpected NUMA node line %q", line)
+		}
+		nodeID, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse NUMA node id from %q: %w", line, err)
+		}
+		cpus, err := cpuset.Parse(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse cpulist %q for NUMA node %d: %w", fields[1], nodeID, err)
+		}
+		numaCPUs[nodeID] = cpus
+	}
+	return numaCPUs, nil
+}
+
 func getStressPod(nodeName string, cpus int) *corev1.Pod {
 	cpuCount := fmt.Sprintf("%d", cpus)
 	return &corev1.Pod{
@@ -1264,6 +2031,73 @@ func getStressPod(nodeName string, cpus int) *corev1.Pod {
 	}
 }
 
+// getLifecycleHookPod returns a guaranteed stress pod whose main container
+// records the cpuset it observes in its postStart and preStop lifecycle
+// hooks to files on a shared emptyDir, plus a "checker" sidecar that mounts
+// the same volume so tests can read those files without racing the main
+// container's own exec probes.
+func getLifecycleHookPod(nodeName string, cpus int) *corev1.Pod {
+	pod := promotePodToGuaranteed(getStressPod(nodeName, cpus))
+
+	const sharedDir = "/shared"
+	pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+		Name:         "hook-cpuset",
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+	})
+
+	main := &pod.Spec.Containers[0]
+	main.VolumeMounts = append(main.VolumeMounts, corev1.VolumeMount{Name: "hook-cpuset", MountPath: sharedDir})
+	main.Lifecycle = &corev1.Lifecycle{
+		PostStart: &corev1.LifecycleHandler{
+			Exec: &corev1.ExecAction{Command: []string{"/bin/sh", "-c",
+				fmt.Sprintf("cat /proc/self/status | grep Cpus_allowed_list > %s/poststart.cpus", sharedDir)}},
+		},
+		PreStop: &corev1.LifecycleHandler{
+			Exec: &corev1.ExecAction{Command: []string{"/bin/sh", "-c",
+				fmt.Sprintf("cat /proc/self/status | grep Cpus_allowed_list > %s/prestop.cpus", sharedDir)}},
+		},
+	}
+
+	pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{
+		Name:    "checker",
+		Image:   images.Test(),
+		Command: []string{"/bin/sh", "-c", "sleep infinity"},
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("100m"),
+				corev1.ResourceMemory: resource.MustParse("64Mi"),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("100m"),
+				corev1.ResourceMemory: resource.MustParse("64Mi"),
+			},
+		},
+		VolumeMounts: []corev1.VolumeMount{{Name: "hook-cpuset", MountPath: sharedDir}},
+	})
+
+	return pod
+}
+
+// readHookCpuset polls the checker sidecar of pod until file appears with
+// content, then parses the Cpus_allowed_list line it contains.
+func readHookCpuset(pod *corev1.Pod, file string) (cpuset.CPUSet, error) {
+	var content string
+	Eventually(func() string {
+		out, err := pods.ExecCommandOnPod(testclient.K8sClient, pod, "checker", []string{"/bin/sh", "-c", fmt.Sprintf("cat %s 2>/dev/null || true", file)})
+		if err != nil {
+			return ""
+		}
+		content = testutils.ToString(out)
+		return content
+	}).WithTimeout(2*time.Minute).WithPolling(2*time.Second).ShouldNot(BeEmpty(), fmt.Sprintf("hook never wrote %s", file))
+
+	parts := strings.Split(strings.TrimSpace(content), ":")
+	if len(parts) != 2 {
+		return cpuset.CPUSet{}, fmt.Errorf("unexpected Cpus_allowed_list line %q", content)
+	}
+	return cpuset.Parse(strings.TrimSpace(parts[1]))
+}
+
 func promotePodToGuaranteed(pod *corev1.Pod) *corev1.Pod {
 	for idx := 0; idx < len(pod.Spec.Containers); idx++ {
 		cnt := &pod.Spec.Containers[idx] // shortcut
@@ -1396,17 +2230,51 @@ func getCPUswithLoadBalanceDisabled(ctx context.Context, targetNode *corev1.Node
 	return cpusWithoutDomain, nil
 }
 
-// getPodCpus return cpus used based on taskset
+// getPodCpus returns the cpuset string the kubelet's pod-resources API
+// reports for the pod's first container.
 func getPodCpus(testpod *corev1.Pod) (string, error) {
-	tasksetcmd := []string{"taskset", "-pc", "1"}
-	testpodCpusByte, err := pods.ExecCommandOnPod(testclient.K8sClient, testpod, testpod.Spec.Containers[0].Name, tasksetcmd)
+	node, err := nodes.GetByName(testpod.Spec.NodeName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get node %q: %w", testpod.Spec.NodeName, err)
+	}
+	podResources, err := podresources.List(context.TODO(), node)
 	if err != nil {
 		return "", err
 	}
-	testpodCpusStr := string(testpodCpusByte)
-	parts := strings.Split(strings.TrimSpace(testpodCpusStr), ":")
-	cpus := strings.TrimSpace(parts[1])
-	return cpus, err
+	containerName := testpod.Spec.Containers[0].Name
+	containerResources, found := podresources.Lookup(podResources, testpod.Namespace, testpod.Name, containerName)
+	if !found {
+		return "", fmt.Errorf("pod-resources API has no entry for %s/%s container %q", testpod.Namespace, testpod.Name, containerName)
+	}
+	return cpuset.New(containerResources.CPUIDs...).String(), nil
+}
+
+// assertReservedOnlyInfraCgroup builds the pod's cgroup tree and asserts that
+// the infra/pause container is pinned to exactly the reserved CPUs, conmon
+// cgroups stay within the reserved set, and application containers never
+// overlap it.
+func assertReservedOnlyInfraCgroup(ctx context.Context, workerRTNode *corev1.Node, testpod *corev1.Pod, cgroupV2 bool, reservedCPUSet cpuset.CPUSet) {
+	var tree *cgroup.PodCgroupTree
+	Eventually(func() error {
+		var err error
+		tree, err = cgroup.BuildPodCgroupTree(ctx, workerRTNode, testpod, cgroupV2)
+		return err
+	}, cluster.ComputeTestTimeout(30*time.Second, RunningOnSingleNode), 5*time.Second).Should(Succeed())
+
+	By("checking the infra container runs exactly on the reserved CPUs")
+	Expect(tree.Infra.CPUSet.List()).To(Equal(reservedCPUSet.List()))
+
+	By("checking conmon cgroups are confined to the reserved CPUs")
+	for _, conmon := range tree.Conmon {
+		Expect(conmon.CPUSet.IsSubsetOf(reservedCPUSet)).To(BeTrue(),
+			"conmon cgroup %q cpuset %s is not a subset of the reserved set %s", conmon.Path, conmon.CPUSet, reservedCPUSet)
+	}
+
+	By("checking application containers never run on the reserved CPUs")
+	for _, app := range tree.Application {
+		Expect(app.CPUSet.Intersection(reservedCPUSet).IsEmpty()).To(BeTrue(),
+			"application container %q cpuset %s overlaps the reserved set %s", app.Name, app.CPUSet, reservedCPUSet)
+	}
 }
 
 // checkSchedulingDomains Check cpus are part of any scheduling domain
@@ -1425,6 +2293,110 @@ func checkSchedulingDomains(workerRTNode *corev1.Node, podCpus cpuset.CPUSet, te
 	}).WithTimeout(2*time.Minute).WithPolling(5*time.Second).ShouldNot(HaveOccurred(), errMsg)
 }
 
+// domainSnapshot maps each scheduling-domain level's name (e.g. "SMT", "MC",
+// "NUMA") to the union of the CPUs every domain at that level spans on one
+// node, so callers can assert on the whole hierarchy instead of only the
+// /proc/schedstat view checkSchedulingDomains above already covers.
+//
+// getDomainSnapshot reads the debugfs scheduling-domain files directly; it
+// does not go through pkg/performanceprofile/utils/schedstat, which
+// checkSchedulingDomains uses for the /proc/schedstat view.
+type domainSnapshot map[string]cpuset.CPUSet
+
+// getDomainSnapshot reads every cpu*/domain*/{name,span} triple under
+// /sys/kernel/debug/sched/domains on workerNode and folds them into one
+// CPUSet per domain level name.
+func getDomainSnapshot(ctx context.Context, workerNode *corev1.Node) (domainSnapshot, error) {
+	cmd := []string{
+		"/bin/bash", "-c",
+		"for d in /sys/kernel/debug/sched/domains/cpu*/domain*; do echo \"$(cat $d/name) $(cat $d/span)\"; done",
+	}
+	out, err := nodes.ExecCommand(ctx, workerNode, cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the scheduling domain hierarchy: %w", err)
+	}
+
+	snapshot := domainSnapshot{}
+	for _, line := range strings.Split(strings.TrimSpace(testutils.ToString(out)), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		fields := strings.SplitN(line, " ", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		name, span := fields[0], strings.TrimSpace(fields[1])
+		spanSet, err := cpuset.Parse(span)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse span %q for domain %q: %w", span, name, err)
+		}
+		if existing, ok := snapshot[name]; ok {
+			snapshot[name] = existing.Union(spanSet)
+		} else {
+			snapshot[name] = spanSet
+		}
+	}
+	return snapshot, nil
+}
+
+// assertDomainSpanExcludes fails unless every domain level in snapshot
+// excludes all of excluded, i.e. the CPUs were pulled out of load balancing
+// at every level and not only the top one.
+func assertDomainSpanExcludes(snapshot domainSnapshot, excluded cpuset.CPUSet) {
+	for name, span := range snapshot {
+		overlap := span.Intersection(excluded)
+		Expect(overlap.IsEmpty()).To(BeTrue(), "scheduling domain %q still spans excluded CPUs %s", name, overlap)
+	}
+}
+
+// assertNoDomainCrossesNUMA fails if any domain level below the top-level
+// "NUMA" domain mixes CPUs from more than one of numaCPUs' nodes, i.e.
+// cpu-partitioning did not accidentally merge two NUMA domains together.
+func assertNoDomainCrossesNUMA(snapshot domainSnapshot, numaCPUs map[int]cpuset.CPUSet) {
+	for name, span := range snapshot {
+		if name == "NUMA" {
+			continue // the NUMA-level domain is expected to span every node
+		}
+		nodesSpanned := 0
+		for _, nodeCPUs := range numaCPUs {
+			if !span.Intersection(nodeCPUs).IsEmpty() {
+				nodesSpanned++
+			}
+		}
+		Expect(nodesSpanned).To(BeNumerically("<=", 1), "scheduling domain %q spans more than one NUMA node", name)
+	}
+}
+
+// diffDomains returns a human-readable, sorted list of domain levels whose
+// span changed between before and after, to help debug load-balancer
+// regressions after kernel/RHCOS bumps.
+func diffDomains(before, after domainSnapshot) string {
+	names := map[string]struct{}{}
+	for name := range before {
+		names[name] = struct{}{}
+	}
+	for name := range after {
+		names[name] = struct{}{}
+	}
+
+	var diffs []string
+	for name := range names {
+		b, hadBefore := before[name]
+		a, hadAfter := after[name]
+		switch {
+		case hadBefore && !hadAfter:
+			diffs = append(diffs, fmt.Sprintf("%s: removed (was %s)", name, b))
+		case !hadBefore && hadAfter:
+			diffs = append(diffs, fmt.Sprintf("%s: added (now %s)", name, a))
+		case !b.Equals(a):
+			diffs = append(diffs, fmt.Sprintf("%s: %s -> %s", name, b, a))
+		}
+	}
+	sort.Strings(diffs)
+	return strings.Join(diffs, "\n")
+}
+
 // busyCpuImageEnv return busycpus image used for crio quota annotations test
 // This is required for running tests on disconnected environment where images are mirrored
 // in private registries.
@@ -0,0 +1,149 @@
+package cgroup
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/cpuset"
+
+	testutils "github.com/openshift/cluster-node-tuning-operator/test/e2e/performanceprofile/functests/utils"
+	"github.com/openshift/cluster-node-tuning-operator/test/e2e/performanceprofile/functests/utils/nodes"
+	"github.com/openshift/cluster-node-tuning-operator/test/e2e/performanceprofile/functests/utils/pods"
+)
+
+// ContainerCgroup is the parsed subset of a single container's cgroup
+// interface files that tests care about.
+type ContainerCgroup struct {
+	Name        string
+	Path        string
+	CPUSet      cpuset.CPUSet
+	MemoryLimit string
+	CPUMax      string
+}
+
+// PodCgroupTree enumerates the cgroups CRI-O creates for one pod: the
+// sandbox/infra (pause) container, one conmon cgroup per container, and the
+// application containers themselves. BuildPodCgroupTree fills it in
+// uniformly for the cgroupv1 hierarchy (/sys/fs/cgroup/cpuset/...) and the
+// cgroupv2 unified hierarchy (kubepods.slice/.../crio-*.scope), so callers
+// no longer have to hand-parse `find` output per cgroup version.
+type PodCgroupTree struct {
+	Infra       ContainerCgroup
+	Conmon      []ContainerCgroup
+	Application []ContainerCgroup
+}
+
+// BuildPodCgroupTree locates every crio-* cgroup directory for pod on
+// workerNode and classifies each one into the infra/conmon/application
+// buckets of a PodCgroupTree, parsing cpuset.cpus, the memory limit and
+// cpu.max (or their cgroupv1 equivalents) along the way. Unlike
+// ControllersGetter.Container, it has no container name to key off of for
+// the infra/conmon cgroups, so it locates and parses every cgroup directly
+// rather than going through the getter.
+func BuildPodCgroupTree(ctx context.Context, workerNode *corev1.Node, pod *corev1.Pod, cgroupV2 bool) (*PodCgroupTree, error) {
+	podUID := strings.ReplaceAll(string(pod.UID), "-", "_")
+
+	cpusetPath := "/rootfs/sys/fs/cgroup/cpuset"
+	if cgroupV2 {
+		cpusetPath = "/rootfs/sys/fs/cgroup/kubepods.slice"
+	}
+
+	out, err := nodes.ExecCommand(ctx, workerNode, []string{"/bin/bash", "-c", fmt.Sprintf("find %s -name *%s*", cpusetPath, podUID)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate the cgroup for pod %q: %w", podUID, err)
+	}
+	podCgroup := strings.TrimSpace(testutils.ToString(out))
+	if podCgroup == "" {
+		return nil, fmt.Errorf("cannot find cgroup for pod %q", podUID)
+	}
+
+	out, err = nodes.ExecCommand(ctx, workerNode, []string{"/bin/bash", "-c", fmt.Sprintf("find %s -name crio-*", podCgroup)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list container cgroups under %q: %w", podCgroup, err)
+	}
+	dirs := strings.Split(strings.Trim(testutils.ToString(out), "\n"), "\n")
+
+	containerIDs := make(map[string]string, len(pod.Spec.Containers))
+	for _, c := range pod.Spec.Containers {
+		id, err := pods.GetContainerIDByName(pod, c.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve the container ID for %q: %w", c.Name, err)
+		}
+		containerIDs[id] = c.Name
+	}
+
+	tree := &PodCgroupTree{}
+	for _, dir := range dirs {
+		dir = strings.TrimSpace(dir)
+		if dir == "" {
+			continue
+		}
+
+		cc, err := parseContainerCgroup(ctx, workerNode, dir, cgroupV2)
+		if err != nil {
+			return nil, err
+		}
+
+		switch name, isApplication := matchesContainerID(dir, containerIDs); {
+		case strings.Contains(dir, "conmon"):
+			tree.Conmon = append(tree.Conmon, cc)
+		case isApplication:
+			cc.Name = name
+			tree.Application = append(tree.Application, cc)
+		default:
+			tree.Infra = cc
+		}
+	}
+
+	return tree, nil
+}
+
+func matchesContainerID(dir string, containerIDs map[string]string) (string, bool) {
+	for id, name := range containerIDs {
+		if strings.Contains(dir, id) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func parseContainerCgroup(ctx context.Context, workerNode *corev1.Node, dir string, cgroupV2 bool) (ContainerCgroup, error) {
+	cc := ContainerCgroup{Path: dir}
+
+	cpus, err := readCgroupFile(ctx, workerNode, dir, "cpuset.cpus")
+	if err != nil {
+		return cc, err
+	}
+	cc.CPUSet, err = cpuset.Parse(cpus)
+	if err != nil {
+		return cc, fmt.Errorf("failed to parse cpuset.cpus under %q: %w", dir, err)
+	}
+
+	memoryDir, cpuDir := dir, dir
+	memoryFile, cpuMaxFile := "memory.max", "cpu.max"
+	if !cgroupV2 {
+		// cgroupv1 keeps each controller in its own hierarchy and dir is the
+		// cpuset one, so the memory and cpu files live in sibling trees.
+		memoryDir = strings.Replace(dir, "/sys/fs/cgroup/cpuset/", "/sys/fs/cgroup/memory/", 1)
+		cpuDir = strings.Replace(dir, "/sys/fs/cgroup/cpuset/", "/sys/fs/cgroup/cpu/", 1)
+		memoryFile, cpuMaxFile = "memory.limit_in_bytes", "cpu.cfs_quota_us"
+	}
+	if cc.MemoryLimit, err = readCgroupFile(ctx, workerNode, memoryDir, memoryFile); err != nil {
+		return cc, err
+	}
+	if cc.CPUMax, err = readCgroupFile(ctx, workerNode, cpuDir, cpuMaxFile); err != nil {
+		return cc, err
+	}
+
+	return cc, nil
+}
+
+func readCgroupFile(ctx context.Context, workerNode *corev1.Node, dir, file string) (string, error) {
+	out, err := nodes.ExecCommand(ctx, workerNode, []string{"/bin/bash", "-c", fmt.Sprintf("cat %s/%s", dir, file)})
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s under %q: %w", file, dir, err)
+	}
+	return strings.TrimSpace(testutils.ToString(out)), nil
+}
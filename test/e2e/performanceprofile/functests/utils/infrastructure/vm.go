@@ -2,11 +2,11 @@ package infrastructure
 
 import (
 	"context"
-	"strings"
+	"io"
 
 	corev1 "k8s.io/api/core/v1"
 
-	"github.com/openshift/cluster-node-tuning-operator/test/e2e/performanceprofile/functests/utils/nodes"
+	"github.com/openshift/cluster-node-tuning-operator/test/e2e/performanceprofile/functests/utils/node_inspector"
 )
 
 // IsVM checks if a given node's underlying infrastructure is a VM
@@ -15,15 +15,12 @@ func IsVM(ctx context.Context, node *corev1.Node) (bool, error) {
 		"/usr/sbin/chroot",
 		"/rootfs",
 		"/bin/bash", "-c",
-		"systemd-detect-virt > /dev/null; echo $?",
+		"systemd-detect-virt",
 	}
-	output, err := nodes.ExecCommand(ctx, node, cmd)
+	exitCode, err := node_inspector.ExecStream(ctx, node, node_inspector.ExecOptions{Command: cmd, Stdout: io.Discard})
 	if err != nil {
 		return false, err
 	}
 
-	statusCode := strings.TrimSpace(string(output))
-	isVM := statusCode == "0"
-
-	return isVM, nil
+	return exitCode == 0, nil
 }
@@ -0,0 +1,122 @@
+package node_inspector
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	testutils "github.com/openshift/cluster-node-tuning-operator/test/e2e/performanceprofile/functests/utils"
+	testclient "github.com/openshift/cluster-node-tuning-operator/test/e2e/performanceprofile/functests/utils/client"
+	"github.com/openshift/cluster-node-tuning-operator/test/e2e/performanceprofile/functests/utils/pods"
+)
+
+// ephemeralPodReadyTimeout bounds how long EphemeralPodBackend waits for its
+// per-invocation debug pod to become ready before giving up.
+const ephemeralPodReadyTimeout = 2 * time.Minute
+
+// DaemonSetBackend executes commands against the node inspector DaemonSet pod
+// already running on the target node. It is the default Backend and requires
+// the DaemonSet created by Create to be scheduled cluster-wide.
+type DaemonSetBackend struct{}
+
+func (DaemonSetBackend) Exec(ctx context.Context, node *corev1.Node, opts ExecOptions) (int, error) {
+	ok, err := isRunning(testclient.DataPlaneClient, testutils.NodeInspectorNamespace, testutils.NodeInspectorName)
+	if err != nil || !ok {
+		return -1, err
+	}
+	pod, err := getDaemonPodByNode(node)
+	if err != nil {
+		return -1, err
+	}
+	return execInPod(ctx, pod, opts)
+}
+
+// EphemeralPodBackend executes commands by spawning a per-invocation
+// privileged pod pinned to the target node (via NodeName) with the same
+// /rootfs mount as the DaemonSet, and tearing it down afterwards. Use it when
+// the DaemonSet cannot be scheduled - tainted nodes without matching
+// tolerations, SCC/PSP denial, or restricted clusters (e.g. HyperShift worker
+// pools) that disallow cluster-wide DaemonSets.
+type EphemeralPodBackend struct {
+	// Image overrides the node inspector image used for the ephemeral pod.
+	// When empty, testutils.NodeInspectorImage is used; the image of an
+	// existing DaemonSet is not looked up.
+	Image string
+}
+
+func (b EphemeralPodBackend) Exec(ctx context.Context, node *corev1.Node, opts ExecOptions) (int, error) {
+	pod := b.ephemeralPod(node)
+	if err := testclient.DataPlaneClient.Create(ctx, pod); err != nil {
+		return -1, fmt.Errorf("failed to create ephemeral debug pod on node %q: %w", node.Name, err)
+	}
+	defer func() {
+		_ = testclient.DataPlaneClient.Delete(context.Background(), pod)
+	}()
+
+	readyPod, err := pods.WaitForCondition(ctx, client.ObjectKeyFromObject(pod), corev1.PodReady, corev1.ConditionTrue, ephemeralPodReadyTimeout)
+	if err != nil {
+		return -1, fmt.Errorf("ephemeral debug pod on node %q never became ready: %w", node.Name, err)
+	}
+	pod = readyPod
+
+	return execInPod(ctx, pod, opts)
+}
+
+func (b EphemeralPodBackend) ephemeralPod(node *corev1.Node) *corev1.Pod {
+	image := b.Image
+	if image == "" {
+		image = testutils.NodeInspectorImage
+	}
+	mountPropagation := corev1.MountPropagationHostToContainer
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "node-inspector-debug-",
+			Namespace:    testutils.NodeInspectorNamespace,
+			Labels: map[string]string{
+				"name": testutils.NodeInspectorName + "-ephemeral",
+			},
+		},
+		Spec: corev1.PodSpec{
+			NodeName:                      node.Name,
+			HostPID:                       true,
+			HostNetwork:                   true,
+			RestartPolicy:                 corev1.RestartPolicyNever,
+			TerminationGracePeriodSeconds: pointer.Int64(0),
+			Containers: []corev1.Container{
+				{
+					Name:            "node-daemon",
+					Image:           image,
+					Command:         []string{"/bin/bash", "-c", "sleep INF"},
+					ImagePullPolicy: corev1.PullAlways,
+					SecurityContext: &corev1.SecurityContext{
+						Privileged:             pointer.Bool(true),
+						ReadOnlyRootFilesystem: pointer.Bool(true),
+					},
+					VolumeMounts: []corev1.VolumeMount{
+						{
+							MountPath:        "/rootfs",
+							Name:             "rootfs",
+							MountPropagation: &mountPropagation,
+						},
+					},
+				},
+			},
+			Volumes: []corev1.Volume{
+				{
+					Name: "rootfs",
+					VolumeSource: corev1.VolumeSource{
+						HostPath: &corev1.HostPathVolumeSource{Path: "/"},
+					},
+				},
+			},
+			Tolerations: []corev1.Toleration{
+				{Operator: corev1.TolerationOpExists},
+			},
+		},
+	}
+}
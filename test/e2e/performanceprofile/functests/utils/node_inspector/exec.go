@@ -0,0 +1,116 @@
+package node_inspector
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/client-go/util/exec"
+
+	testutils "github.com/openshift/cluster-node-tuning-operator/test/e2e/performanceprofile/functests/utils"
+	testclient "github.com/openshift/cluster-node-tuning-operator/test/e2e/performanceprofile/functests/utils/client"
+)
+
+// ExecOptions configures a Backend exec call. Stdout/Stderr receive the
+// respective streams of the remote command as they arrive, instead of being
+// buffered and conflated the way WaitForPodOutput does.
+type ExecOptions struct {
+	Command []string
+	Stdin   io.Reader
+	Stdout  io.Writer
+	Stderr  io.Writer
+	TTY     bool
+}
+
+// backendEnvVar selects which Backend ExecStream/ExecCommand use. It is
+// intended for clusters (e.g. hosted-control-plane worker pools) where the
+// node inspector DaemonSet cannot be scheduled cluster-wide.
+const backendEnvVar = "NodeInspectorMode"
+
+// ephemeralBackendMode is the backendEnvVar value that selects
+// EphemeralPodBackend instead of the default DaemonSetBackend.
+const ephemeralBackendMode = "ephemeral"
+
+// Backend executes a command against a given node and streams back its
+// output and exit code.
+type Backend interface {
+	Exec(ctx context.Context, node *corev1.Node, opts ExecOptions) (int, error)
+}
+
+// currentBackend returns the Backend selected by backendEnvVar, defaulting to
+// DaemonSetBackend.
+func currentBackend() Backend {
+	if os.Getenv(backendEnvVar) == ephemeralBackendMode {
+		return EphemeralPodBackend{}
+	}
+	return DaemonSetBackend{}
+}
+
+// ExecStream runs opts.Command against the given node using the currently
+// selected Backend, streaming stdout/stderr independently to
+// opts.Stdout/opts.Stderr as they are produced. It returns the container's
+// exit code, recovered from exec.CodeExitError when the remote command exits
+// non-zero.
+func ExecStream(ctx context.Context, node *corev1.Node, opts ExecOptions) (int, error) {
+	return currentBackend().Exec(ctx, node, opts)
+}
+
+// ExecCommand is a back-compat shim over ExecStream that collects stdout into
+// a single buffer, matching the historical API. New callers should prefer
+// ExecStream so stdout and stderr are not conflated and the exit code is not
+// hidden.
+func ExecCommand(ctx context.Context, node *corev1.Node, command []string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	_, err := ExecStream(ctx, node, ExecOptions{
+		Command: command,
+		Stdout:  &stdout,
+		Stderr:  &stderr,
+	})
+	if err != nil {
+		return stdout.Bytes(), err
+	}
+	return stdout.Bytes(), nil
+}
+
+// execInPod runs opts.Command inside the first container of pod via the SPDY
+// exec subresource, streaming output and recovering the exit code the same
+// way regardless of which Backend located the pod.
+func execInPod(ctx context.Context, pod *corev1.Pod, opts ExecOptions) (int, error) {
+	req := testclient.K8sClient.CoreV1().RESTClient().
+		Post().
+		Resource("pods").
+		Name(pod.Name).
+		Namespace(pod.Namespace).
+		SubResource("exec")
+	req.VersionedParams(&corev1.PodExecOptions{
+		Container: pod.Spec.Containers[0].Name,
+		Command:   opts.Command,
+		Stdin:     opts.Stdin != nil,
+		Stdout:    opts.Stdout != nil,
+		Stderr:    opts.Stderr != nil,
+		TTY:       opts.TTY,
+	}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(testclient.RestConfig, "POST", req.URL())
+	if err != nil {
+		return -1, err
+	}
+
+	err = executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdin:  opts.Stdin,
+		Stdout: opts.Stdout,
+		Stderr: opts.Stderr,
+		Tty:    opts.TTY,
+	})
+	if err == nil {
+		return 0, nil
+	}
+	if codeErr, ok := err.(exec.CodeExitError); ok {
+		return codeErr.ExitStatus(), nil
+	}
+	return -1, err
+}
@@ -0,0 +1,104 @@
+package node_inspector
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	. "github.com/onsi/gomega"
+)
+
+// FanOutOptions configures ExecOnNodes.
+type FanOutOptions struct {
+	// Workers bounds how many nodes are exec'd against concurrently. Zero or
+	// negative defaults to len(nodes), i.e. fully parallel.
+	Workers int
+	// PerNodeTimeout bounds how long a single node's exec is allowed to run.
+	// Zero means no per-node timeout beyond the parent context.
+	PerNodeTimeout time.Duration
+}
+
+// NodeResult is the outcome of running a command against a single node via
+// ExecOnNodes.
+type NodeResult struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+	Err      error
+}
+
+// ExecOnNodes runs cmd concurrently across nodes, bounded by opts.Workers,
+// and returns a map of node name to NodeResult. Results are independent of
+// completion order. If ctx is cancelled, outstanding execs are cancelled and
+// their NodeResult.Err is set to ctx.Err().
+func ExecOnNodes(ctx context.Context, nodes []*corev1.Node, cmd []string, opts FanOutOptions) (map[string]NodeResult, error) {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = len(nodes)
+	}
+	if workers == 0 {
+		return map[string]NodeResult{}, nil
+	}
+
+	results := make(map[string]NodeResult, len(nodes))
+	var mu sync.Mutex
+
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+
+	for _, node := range nodes {
+		node := node
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			nodeCtx := ctx
+			cancel := func() {}
+			if opts.PerNodeTimeout > 0 {
+				nodeCtx, cancel = context.WithTimeout(ctx, opts.PerNodeTimeout)
+			}
+			defer cancel()
+
+			result := execOne(nodeCtx, node, cmd)
+
+			mu.Lock()
+			results[node.Name] = result
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	return results, ctx.Err()
+}
+
+func execOne(ctx context.Context, node *corev1.Node, cmd []string) NodeResult {
+	var stdout, stderr bytes.Buffer
+	exitCode, err := ExecStream(ctx, node, ExecOptions{
+		Command: cmd,
+		Stdout:  &stdout,
+		Stderr:  &stderr,
+	})
+	return NodeResult{
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		ExitCode: exitCode,
+		Err:      err,
+	}
+}
+
+// ExpectAllNodes is a Ginkgo-friendly assertion helper that fails the current
+// spec with a per-node breakdown if predicate returns false for any result.
+func ExpectAllNodes(results map[string]NodeResult, predicate func(NodeResult) bool) {
+	failing := map[string]NodeResult{}
+	for node, result := range results {
+		if !predicate(result) {
+			failing[node] = result
+		}
+	}
+	Expect(failing).To(BeEmpty(), "expected predicate to hold for all nodes, but it failed for: %v", failing)
+}
@@ -19,8 +19,7 @@ import (
 	testutils "github.com/openshift/cluster-node-tuning-operator/test/e2e/performanceprofile/functests/utils"
 	testclient "github.com/openshift/cluster-node-tuning-operator/test/e2e/performanceprofile/functests/utils/client"
 	"github.com/openshift/cluster-node-tuning-operator/test/e2e/performanceprofile/functests/utils/daemonset"
-	testlog "github.com/openshift/cluster-node-tuning-operator/test/e2e/performanceprofile/functests/utils/log"
-	testpods "github.com/openshift/cluster-node-tuning-operator/test/e2e/performanceprofile/functests/utils/pods"
+	"github.com/openshift/cluster-node-tuning-operator/test/e2e/performanceprofile/functests/utils/retry"
 )
 
 const serviceAccountSuffix = "sa"
@@ -28,29 +27,43 @@ const clusterRoleSuffix = "cr"
 const clusterRoleBindingSuffix = "crb"
 
 func Create(cli client.Client, namespace, name, image string) error {
+	if shouldRunPreflight() {
+		report, err := Preflight(context.Background(), cli, namespace, name)
+		if err != nil {
+			return err
+		}
+		klog.Info(report.String())
+		if report.Failed() {
+			return report.Error()
+		}
+	}
+
+	ctx := context.Background()
 	serviceAccountName := fmt.Sprintf("%s-%s", name, serviceAccountSuffix)
 	sa := createServiceAccount(serviceAccountName, namespace)
-	if err := cli.Create(context.Background(), sa); err != nil && !errors.IsAlreadyExists(err) {
+	if err := retry.CreateWithRetry(ctx, cli, sa, retry.DefaultBackoff); err != nil {
 		return err
 	}
 	clusterRoleName := fmt.Sprintf("%s-%s", name, clusterRoleSuffix)
 	cr := createClusterRole(clusterRoleName)
-	if err := cli.Create(context.Background(), cr); err != nil && !errors.IsAlreadyExists(err) {
+	if err := retry.CreateWithRetry(ctx, cli, cr, retry.DefaultBackoff); err != nil {
 		return err
 	}
 	clusterRoleBindingName := fmt.Sprintf("%s-%s", name, clusterRoleBindingSuffix)
 	rb := createClusterRoleBinding(clusterRoleBindingName, namespace, serviceAccountName, clusterRoleName)
-	if err := cli.Create(context.Background(), rb); err != nil && !errors.IsAlreadyExists(err) {
+	if err := retry.CreateWithRetry(ctx, cli, rb, retry.DefaultBackoff); err != nil {
 		return err
 	}
 	ds := createDaemonSet(name, namespace, serviceAccountName, image)
-	if err := cli.Create(context.Background(), ds); err != nil {
+	if err := retry.CreateWithRetry(ctx, cli, ds, retry.DefaultBackoff); err != nil {
 		if !errors.IsAlreadyExists(err) {
 			return err
 		}
 		klog.Infof("The node inspector daemonset was not expected to be running")
 	}
-	if err := daemonset.WaitToBeRunning(cli, namespace, name); err != nil {
+	if err := retry.Do(ctx, retry.DefaultBackoff, func() error {
+		return daemonset.WaitToBeRunning(cli, namespace, name)
+	}); err != nil {
 		return err
 	}
 
@@ -58,12 +71,13 @@ func Create(cli client.Client, namespace, name, image string) error {
 }
 
 func Delete(cli client.Client, namespace, name string) error {
+	ctx := context.Background()
 	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
-	if err := cli.Delete(context.Background(), ns); err != nil && !errors.IsNotFound(err) {
+	if err := retry.DeleteWithRetry(ctx, cli, ns, retry.DefaultBackoff); err != nil {
 		return err
 	}
 	cr := &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("%s-%s", name, clusterRoleSuffix)}}
-	if err := cli.Delete(context.Background(), cr); err != nil && !errors.IsNotFound(err) {
+	if err := retry.DeleteWithRetry(ctx, cli, cr, retry.DefaultBackoff); err != nil {
 		return err
 	}
 	return nil
@@ -91,22 +105,6 @@ func getDaemonPodByNode(node *corev1.Node) (*corev1.Pod, error) {
 	return &pods.Items[0], nil
 }
 
-// ExecCommand executing the command on a daemon pod of the given node
-func ExecCommand(ctx context.Context, node *corev1.Node, command []string) ([]byte, error) {
-	// Ensure the node inspector is running
-	ok, err := isRunning(testclient.DataPlaneClient, testutils.NodeInspectorNamespace, testutils.NodeInspectorName)
-	if err != nil || !ok {
-		return nil, err
-	}
-	pod, err := getDaemonPodByNode(node)
-	if err != nil {
-		return nil, err
-	}
-	testlog.Infof("found daemon pod %s for node %s", pod.Name, node.Name)
-
-	return testpods.WaitForPodOutput(ctx, testclient.K8sClient, pod, command)
-}
-
 func createDaemonSet(name, namespace, serviceAccountName, image string) *appsv1.DaemonSet {
 	MountPropagationHostToContainer := corev1.MountPropagationHostToContainer
 	return &appsv1.DaemonSet{
@@ -0,0 +1,309 @@
+package node_inspector
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	securityv1 "github.com/openshift/api/security/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// preflightEnvVar, when set to a truthy value, makes Create run Preflight
+// before attempting to roll out the node inspector DaemonSet.
+const preflightEnvVar = "NODE_INSPECTOR_PREFLIGHT"
+
+// minimumOpenShiftMinor is the lowest OpenShift minor version (4.x) known to
+// support the PerformanceProfile CRD shapes these e2e tests rely on.
+const minimumOpenShiftMinor = 12
+
+// probePodTimeout bounds how long checkCanaryPodAdmission/
+// checkHostPathAvailability wait for their probe pod to run to completion
+// before concluding the check failed.
+const probePodTimeout = 1 * time.Minute
+
+// CheckStatus is the outcome of a single preflight check.
+type CheckStatus string
+
+const (
+	CheckPass CheckStatus = "Pass"
+	CheckFail CheckStatus = "Fail"
+	CheckSkip CheckStatus = "Skip"
+)
+
+// CheckResult is the structured outcome of a single preflight check.
+type CheckResult struct {
+	Name    string
+	Status  CheckStatus
+	Message string
+	Err     error
+}
+
+// Report aggregates the outcome of every preflight check that ran.
+type Report struct {
+	Results []CheckResult
+}
+
+// Failed returns true if at least one check in the report failed.
+func (r Report) Failed() bool {
+	for _, res := range r.Results {
+		if res.Status == CheckFail {
+			return true
+		}
+	}
+	return false
+}
+
+// Error renders the report as a single aggregated error, or nil if every
+// check passed or was skipped.
+func (r Report) Error() error {
+	if !r.Failed() {
+		return nil
+	}
+	var sb strings.Builder
+	sb.WriteString("node inspector preflight checks failed:")
+	for _, res := range r.Results {
+		if res.Status != CheckFail {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n  - %s: %s", res.Name, res.Message)
+	}
+	return fmt.Errorf("%s", sb.String())
+}
+
+// String renders a human readable pass/fail/skip summary, one line per check.
+func (r Report) String() string {
+	var sb strings.Builder
+	for _, res := range r.Results {
+		fmt.Fprintf(&sb, "[%s] %s: %s\n", res.Status, res.Name, res.Message)
+	}
+	return sb.String()
+}
+
+// checkFunc is a self-contained preflight check. It receives the client and
+// the target namespace/name the DaemonSet is about to be created with.
+type checkFunc func(ctx context.Context, cli client.Client, namespace, name string) CheckResult
+
+// checks is the registry of preflight checks run by Preflight, in order.
+var checks = []checkFunc{
+	checkOpenShiftVersion,
+	checkPrivilegedSCC,
+	checkSchedulableLinuxNodes,
+	checkCanaryPodAdmission,
+	checkHostPathAvailability,
+}
+
+// Preflight runs every registered readiness check against the target cluster
+// and returns an aggregated Report. It does not return an error by itself;
+// callers should inspect Report.Failed()/Report.Error() to decide whether to
+// proceed with the DaemonSet rollout.
+func Preflight(ctx context.Context, cli client.Client, namespace, name string) (Report, error) {
+	report := Report{}
+	for _, check := range checks {
+		report.Results = append(report.Results, check(ctx, cli, namespace, name))
+	}
+	return report, nil
+}
+
+// shouldRunPreflight reports whether Create should run Preflight before
+// attempting to roll out the DaemonSet, based on preflightEnvVar.
+func shouldRunPreflight() bool {
+	enabled, err := strconv.ParseBool(os.Getenv(preflightEnvVar))
+	if err != nil {
+		return false
+	}
+	return enabled
+}
+
+func checkOpenShiftVersion(ctx context.Context, cli client.Client, namespace, name string) CheckResult {
+	const checkName = "openshift-version-compatibility"
+	cv := &configv1.ClusterVersion{}
+	if err := cli.Get(ctx, client.ObjectKey{Name: "version"}, cv); err != nil {
+		if errors.IsNotFound(err) {
+			// Not every cluster this suite targets is OpenShift (e.g. plain
+			// Kubernetes CI); treat the absence of ClusterVersion as "skip".
+			return CheckResult{Name: checkName, Status: CheckSkip, Message: "no ClusterVersion resource found, assuming non-OpenShift cluster"}
+		}
+		return CheckResult{Name: checkName, Status: CheckFail, Message: fmt.Sprintf("failed to get the cluster version: %v", err), Err: err}
+	}
+	major, minor, err := parseMajorMinor(cv.Status.Desired.Version)
+	if err != nil {
+		return CheckResult{Name: checkName, Status: CheckFail, Message: fmt.Sprintf("could not parse desired version %q: %v", cv.Status.Desired.Version, err), Err: err}
+	}
+	if major < 4 || (major == 4 && minor < minimumOpenShiftMinor) {
+		msg := fmt.Sprintf("cluster version %d.%d is older than the minimum supported 4.%d for the PerformanceProfile CRD", major, minor, minimumOpenShiftMinor)
+		return CheckResult{Name: checkName, Status: CheckFail, Message: msg}
+	}
+	return CheckResult{Name: checkName, Status: CheckPass, Message: fmt.Sprintf("cluster version %d.%d is compatible", major, minor)}
+}
+
+func checkPrivilegedSCC(ctx context.Context, cli client.Client, namespace, name string) CheckResult {
+	const checkName = "privileged-scc-present"
+	scc := &securityv1.SecurityContextConstraints{}
+	if err := cli.Get(ctx, client.ObjectKey{Name: "privileged"}, scc); err != nil {
+		if errors.IsNotFound(err) {
+			return CheckResult{Name: checkName, Status: CheckFail, Message: "the \"privileged\" SecurityContextConstraint does not exist", Err: err}
+		}
+		return CheckResult{Name: checkName, Status: CheckFail, Message: fmt.Sprintf("failed to get the \"privileged\" SCC: %v", err), Err: err}
+	}
+	return CheckResult{Name: checkName, Status: CheckPass, Message: "the \"privileged\" SCC is present"}
+}
+
+func checkSchedulableLinuxNodes(ctx context.Context, cli client.Client, namespace, name string) CheckResult {
+	const checkName = "schedulable-linux-nodes"
+	nodeList := &corev1.NodeList{}
+	listOptions := &client.ListOptions{LabelSelector: labels.SelectorFromSet(labels.Set{"kubernetes.io/os": "linux"})}
+	if err := cli.List(ctx, nodeList, listOptions); err != nil {
+		return CheckResult{Name: checkName, Status: CheckFail, Message: fmt.Sprintf("failed to list linux nodes: %v", err), Err: err}
+	}
+	schedulable := 0
+	for _, node := range nodeList.Items {
+		if node.Spec.Unschedulable {
+			continue
+		}
+		schedulable++
+	}
+	if schedulable == 0 {
+		return CheckResult{Name: checkName, Status: CheckFail, Message: "no schedulable nodes match kubernetes.io/os=linux"}
+	}
+	return CheckResult{Name: checkName, Status: CheckPass, Message: fmt.Sprintf("%d schedulable linux node(s) found", schedulable)}
+}
+
+// checkCanaryPodAdmission creates a throwaway host-PID/host-network/
+// privileged pod in the target namespace and waits for it to actually run to
+// completion, to make sure the caller is not just admitted but can get what
+// the DaemonSet needs scheduled and started, then tears it down.
+func checkCanaryPodAdmission(ctx context.Context, cli client.Client, namespace, name string) CheckResult {
+	const checkName = "canary-pod-admission"
+	canary := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-preflight-canary", name),
+			Namespace: namespace,
+		},
+		Spec: corev1.PodSpec{
+			HostPID:                       true,
+			HostNetwork:                   true,
+			RestartPolicy:                 corev1.RestartPolicyNever,
+			TerminationGracePeriodSeconds: pointer.Int64(0),
+			Containers: []corev1.Container{
+				{
+					Name:    "canary",
+					Image:   "registry.access.redhat.com/ubi9/ubi-minimal",
+					Command: []string{"/bin/true"},
+					SecurityContext: &corev1.SecurityContext{
+						Privileged: pointer.Bool(true),
+					},
+				},
+			},
+		},
+	}
+	if err := cli.Create(ctx, canary); err != nil {
+		return CheckResult{Name: checkName, Status: CheckFail, Message: fmt.Sprintf("cannot create host-PID/host-network/privileged pods in %q: %v", namespace, err), Err: err}
+	}
+	defer deleteProbePod(cli, canary)
+
+	if err := waitForPodRunToCompletion(ctx, cli, canary); err != nil {
+		return CheckResult{Name: checkName, Status: CheckFail, Message: fmt.Sprintf("host-PID/host-network/privileged canary pod in %q did not run to completion: %v", namespace, err), Err: err}
+	}
+	return CheckResult{Name: checkName, Status: CheckPass, Message: fmt.Sprintf("host-PID/host-network/privileged pods are admitted and run in %q", namespace)}
+}
+
+// checkHostPathAvailability spawns a short-lived probe pod mounting the host
+// rootfs as a HostPath volume and waits for it to actually run to completion,
+// confirming at least one node both admits and can schedule/mount it, then
+// tears it down.
+func checkHostPathAvailability(ctx context.Context, cli client.Client, namespace, name string) CheckResult {
+	const checkName = "hostpath-rootfs-probe"
+	hostPathDirectory := corev1.HostPathDirectory
+	probe := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-preflight-probe", name),
+			Namespace: namespace,
+		},
+		Spec: corev1.PodSpec{
+			TerminationGracePeriodSeconds: pointer.Int64(0),
+			RestartPolicy:                 corev1.RestartPolicyNever,
+			Containers: []corev1.Container{
+				{
+					Name:    "probe",
+					Image:   "registry.access.redhat.com/ubi9/ubi-minimal",
+					Command: []string{"/bin/true"},
+					VolumeMounts: []corev1.VolumeMount{
+						{Name: "rootfs", MountPath: "/rootfs"},
+					},
+				},
+			},
+			Volumes: []corev1.Volume{
+				{
+					Name: "rootfs",
+					VolumeSource: corev1.VolumeSource{
+						HostPath: &corev1.HostPathVolumeSource{Path: "/", Type: &hostPathDirectory},
+					},
+				},
+			},
+		},
+	}
+	if err := cli.Create(ctx, probe); err != nil {
+		return CheckResult{Name: checkName, Status: CheckFail, Message: fmt.Sprintf("cannot mount /rootfs HostPath volume: %v", err), Err: err}
+	}
+	defer deleteProbePod(cli, probe)
+
+	if err := waitForPodRunToCompletion(ctx, cli, probe); err != nil {
+		return CheckResult{Name: checkName, Status: CheckFail, Message: fmt.Sprintf("/rootfs HostPath probe pod did not run to completion: %v", err), Err: err}
+	}
+	return CheckResult{Name: checkName, Status: CheckPass, Message: "a /rootfs HostPath mount is admitted and mountable on the target nodes"}
+}
+
+// waitForPodRunToCompletion polls pod until it reaches a terminal phase,
+// returning an error if it fails, never leaves Pending (e.g. no node will
+// actually schedule or admit it), or probePodTimeout elapses.
+func waitForPodRunToCompletion(ctx context.Context, cli client.Client, pod *corev1.Pod) error {
+	key := client.ObjectKeyFromObject(pod)
+	return wait.PollUntilContextTimeout(ctx, 2*time.Second, probePodTimeout, true, func(ctx context.Context) (bool, error) {
+		if err := cli.Get(ctx, key, pod); err != nil {
+			return false, err
+		}
+		switch pod.Status.Phase {
+		case corev1.PodSucceeded:
+			return true, nil
+		case corev1.PodFailed:
+			return false, fmt.Errorf("pod %s failed: %s", key, pod.Status.Message)
+		default:
+			return false, nil
+		}
+	})
+}
+
+// deleteProbePod best-effort deletes a canary/probe pod, logging nothing on
+// failure: the pod is a throwaway with a 0s TerminationGracePeriodSeconds and
+// callers cannot act on a cleanup error anyway.
+func deleteProbePod(cli client.Client, pod *corev1.Pod) {
+	_ = cli.Delete(context.Background(), pod, client.GracePeriodSeconds(0))
+}
+
+func parseMajorMinor(v string) (int, int, error) {
+	v = strings.TrimPrefix(v, "v")
+	parts := strings.SplitN(v, ".", 3)
+	if len(parts) < 2 {
+		return 0, 0, fmt.Errorf("unexpected version format %q", v)
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, err
+	}
+	return major, minor, nil
+}
@@ -0,0 +1,47 @@
+// Package parallel provides a small bounded worker-pool primitive for
+// fanning out independent, possibly slow operations (pod create/wait/delete
+// cycles, in particular) without serializing them one at a time.
+package parallel
+
+import "sync"
+
+// Job is a unit of work ParallelExecute dispatches to its worker pool.
+type Job func() error
+
+// ParallelExecute runs jobs across a pool of workers concurrent goroutines
+// and blocks until every job has completed. It returns one error per job,
+// in the same order as jobs, so callers can correlate failures back to the
+// work item that produced them. workers is clamped to [1, len(jobs)].
+func ParallelExecute(jobs []Job, workers int) []error {
+	if len(jobs) == 0 {
+		return nil
+	}
+	if workers <= 0 {
+		workers = 1
+	}
+	if workers > len(jobs) {
+		workers = len(jobs)
+	}
+
+	results := make([]error, len(jobs))
+	indices := make(chan int)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for idx := range indices {
+				results[idx] = jobs[idx]()
+			}
+		}()
+	}
+
+	for idx := range jobs {
+		indices <- idx
+	}
+	close(indices)
+	wg.Wait()
+
+	return results
+}
@@ -0,0 +1,196 @@
+// Package podresources queries the kubelet's PodResources v1 gRPC API
+// (served over /var/lib/kubelet/pod-resources/kubelet.sock) for the
+// per-container CPU/NUMA/device allocation the CPU manager and device
+// plugins actually handed out, and for the node-wide allocatable pool.
+package podresources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	testutils "github.com/openshift/cluster-node-tuning-operator/test/e2e/performanceprofile/functests/utils"
+	"github.com/openshift/cluster-node-tuning-operator/test/e2e/performanceprofile/functests/utils/nodes"
+)
+
+const podResourcesSocket = "/var/lib/kubelet/pod-resources/kubelet.sock"
+
+// podResourcesProtoPath is the v1.PodResourcesLister .proto descriptor
+// grpcurl resolves the List/GetAllocatableResources request/response types
+// from. The kubelet's PodResources gRPC service does not implement server
+// reflection, so grpcurl cannot discover these on its own; the descriptor is
+// vendored into the node inspector image's build alongside grpcurl itself
+// (see call), not part of this checkout.
+const podResourcesProtoPath = "/usr/share/grpcurl/podresources/v1/api.proto"
+
+// ContainerResources is one container's allocation as reported by the
+// PodResources API: the CPU IDs the CPU manager pinned it to, the NUMA
+// nodes those CPUs and any devices sit on, and any extended device-plugin
+// resources it was allocated.
+type ContainerResources struct {
+	Name      string
+	CPUIDs    []int
+	NUMANodes []int
+	Devices   map[string][]string
+}
+
+// PodResources is one pod's ContainerResources, as returned by List.
+type PodResources struct {
+	Namespace  string
+	Name       string
+	Containers []ContainerResources
+}
+
+// AllocatableResources is the node-wide pool GetAllocatableResources
+// reports: every CPU the kubelet currently considers available to the CPU
+// manager, plus allocatable device-plugin resources. Unlike ContainerResources,
+// there is no per-NUMA-node grouping for CPUIDs: the GetAllocatableResources
+// RPC only carries topology information on devices, not on the plain CPU ID
+// list, so it cannot be derived here.
+type AllocatableResources struct {
+	CPUIDs  []int
+	Devices map[string][]string
+}
+
+// wire* mirror the JSON shape grpcurl emits for the PodResourcesLister
+// List/GetAllocatableResources RPCs (protobuf int64 fields such as cpu IDs
+// and NUMA node IDs are serialized as JSON strings/numbers respectively).
+type wireTopology struct {
+	Nodes []struct {
+		ID int `json:"ID"`
+	} `json:"nodes"`
+}
+
+type wireDevice struct {
+	ResourceName string       `json:"resourceName"`
+	DeviceIds    []string     `json:"deviceIds"`
+	Topology     wireTopology `json:"topology"`
+}
+
+type wireContainer struct {
+	Name    string       `json:"name"`
+	CpuIds  []string     `json:"cpuIds"`
+	Devices []wireDevice `json:"devices"`
+}
+
+type wirePod struct {
+	Name       string          `json:"name"`
+	Namespace  string          `json:"namespace"`
+	Containers []wireContainer `json:"containers"`
+}
+
+type wireListResponse struct {
+	PodResources []wirePod `json:"podResources"`
+}
+
+type wireAllocatableResponse struct {
+	CpuIds  []string     `json:"cpuIds"`
+	Devices []wireDevice `json:"devices"`
+}
+
+// List returns every pod's per-container CPU/NUMA/device allocation as
+// currently reported by workerNode's kubelet.
+func List(ctx context.Context, workerNode *corev1.Node) ([]PodResources, error) {
+	var resp wireListResponse
+	if err := call(ctx, workerNode, "v1.PodResourcesLister/List", &resp); err != nil {
+		return nil, err
+	}
+
+	podResources := make([]PodResources, 0, len(resp.PodResources))
+	for _, p := range resp.PodResources {
+		pr := PodResources{Namespace: p.Namespace, Name: p.Name}
+		for _, c := range p.Containers {
+			pr.Containers = append(pr.Containers, toContainerResources(c))
+		}
+		podResources = append(podResources, pr)
+	}
+	return podResources, nil
+}
+
+// GetAllocatableResources returns the CPU/device pool workerNode's kubelet
+// currently considers allocatable, so callers can cross-check it against
+// the reserved/isolated split a PerformanceProfile is supposed to produce.
+func GetAllocatableResources(ctx context.Context, workerNode *corev1.Node) (*AllocatableResources, error) {
+	var resp wireAllocatableResponse
+	if err := call(ctx, workerNode, "v1.PodResourcesLister/GetAllocatableResources", &resp); err != nil {
+		return nil, err
+	}
+
+	allocatable := &AllocatableResources{
+		CPUIDs:  parseCPUIDs(resp.CpuIds),
+		Devices: map[string][]string{},
+	}
+	for _, d := range resp.Devices {
+		allocatable.Devices[d.ResourceName] = append(allocatable.Devices[d.ResourceName], d.DeviceIds...)
+	}
+	return allocatable, nil
+}
+
+// Lookup finds the named container's resources for the named pod within a
+// List result.
+func Lookup(podResources []PodResources, namespace, podName, containerName string) (*ContainerResources, bool) {
+	for _, p := range podResources {
+		if p.Namespace != namespace || p.Name != podName {
+			continue
+		}
+		for _, c := range p.Containers {
+			if c.Name == containerName {
+				cc := c
+				return &cc, true
+			}
+		}
+	}
+	return nil, false
+}
+
+func toContainerResources(c wireContainer) ContainerResources {
+	cr := ContainerResources{Name: c.Name, CPUIDs: parseCPUIDs(c.CpuIds), Devices: map[string][]string{}}
+	numaNodes := map[int]struct{}{}
+	for _, d := range c.Devices {
+		cr.Devices[d.ResourceName] = append(cr.Devices[d.ResourceName], d.DeviceIds...)
+		for _, n := range d.Topology.Nodes {
+			numaNodes[n.ID] = struct{}{}
+		}
+	}
+	for n := range numaNodes {
+		cr.NUMANodes = append(cr.NUMANodes, n)
+	}
+	return cr
+}
+
+func parseCPUIDs(ids []string) []int {
+	cpus := make([]int, 0, len(ids))
+	for _, id := range ids {
+		var cpu int
+		if _, err := fmt.Sscanf(id, "%d", &cpu); err == nil {
+			cpus = append(cpus, cpu)
+		}
+	}
+	return cpus
+}
+
+// call dials podResourcesSocket on workerNode and decodes the RPC's JSON
+// response into out. Unlike the crictl/jq/taskset helpers elsewhere in this
+// suite, it does not chroot into /rootfs: grpcurl is not present on the
+// RHCOS host, so this runs grpcurl from the node inspector image itself
+// (where it is vendored as part of that image's build, not part of this
+// checkout) and reaches the socket through the image's /rootfs hostPath
+// mount instead. Since the PodResources service has no server reflection,
+// method/message descriptors are resolved from podResourcesProtoPath via
+// -proto rather than relying on grpcurl's reflection-based default.
+func call(ctx context.Context, workerNode *corev1.Node, method string, out interface{}) error {
+	cmd := []string{
+		"/bin/bash", "-c",
+		fmt.Sprintf("/usr/bin/grpcurl -plaintext -proto %s -unix /rootfs%s %s", podResourcesProtoPath, podResourcesSocket, method),
+	}
+	raw, err := nodes.ExecCommand(ctx, workerNode, cmd)
+	if err != nil {
+		return fmt.Errorf("failed to call %s over the pod-resources socket: %w", method, err)
+	}
+	if err := json.Unmarshal([]byte(testutils.ToString(raw)), out); err != nil {
+		return fmt.Errorf("failed to unmarshal %s response: %w", method, err)
+	}
+	return nil
+}
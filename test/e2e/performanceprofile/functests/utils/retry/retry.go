@@ -0,0 +1,149 @@
+// Package retry provides small generic wrappers around apiserver calls that
+// transparently retry on transient errors with exponential backoff. It exists
+// so that a single flake talking to an unstable CI cluster does not abort an
+// entire e2e suite.
+package retry
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultBackoff is the exponential backoff callers in this suite pass to the
+// With* helpers and Do. Those helpers take the backoff as a required
+// argument and do not fall back to DefaultBackoff on their own.
+var DefaultBackoff = wait.Backoff{
+	Duration: 500 * time.Millisecond,
+	Factor:   2.0,
+	Jitter:   0.1,
+	Steps:    6,
+}
+
+// IsRetryable reports whether err is a transient apiserver/network error
+// worth retrying: server timeouts, rate limiting, internal errors, or
+// connection resets.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.IsServerTimeout(err) || errors.IsTooManyRequests(err) || errors.IsInternalError(err) || errors.IsTimeout(err) {
+		return true
+	}
+	var netErr net.Error
+	if ok := asNetError(err, &netErr); ok {
+		return true
+	}
+	// connection resets/EOFs surface as plain errors from the transport, not
+	// as typed net.Error values, so fall back to a message check.
+	msg := err.Error()
+	return strings.Contains(msg, "connection reset by peer") ||
+		strings.Contains(msg, "broken pipe") ||
+		strings.Contains(msg, "EOF") ||
+		strings.Contains(msg, "http2: client connection lost")
+}
+
+func asNetError(err error, target *net.Error) bool {
+	for err != nil {
+		if ne, ok := err.(net.Error); ok {
+			*target = ne
+			return true
+		}
+		u, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			return false
+		}
+		err = u.Unwrap()
+	}
+	return false
+}
+
+// CreateWithRetry creates obj, retrying transient errors with backoff until
+// either it succeeds, a non-retryable error is returned, or backoff is
+// exhausted. errors.IsAlreadyExists is not retried and is returned as-is so
+// callers can keep treating "already exists" as success.
+func CreateWithRetry(ctx context.Context, cli client.Client, obj client.Object, backoff wait.Backoff, opts ...client.CreateOption) error {
+	var lastErr error
+	err := wait.ExponentialBackoffWithContext(ctx, backoff, func(ctx context.Context) (bool, error) {
+		lastErr = cli.Create(ctx, obj, opts...)
+		if lastErr == nil || errors.IsAlreadyExists(lastErr) {
+			return true, nil
+		}
+		if !IsRetryable(lastErr) {
+			return false, lastErr
+		}
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+	return lastErr
+}
+
+// DeleteWithRetry deletes obj, retrying transient errors with backoff.
+// errors.IsNotFound is not retried and is returned as-is so callers can keep
+// treating "already gone" as success.
+func DeleteWithRetry(ctx context.Context, cli client.Client, obj client.Object, backoff wait.Backoff, opts ...client.DeleteOption) error {
+	var lastErr error
+	err := wait.ExponentialBackoffWithContext(ctx, backoff, func(ctx context.Context) (bool, error) {
+		lastErr = cli.Delete(ctx, obj, opts...)
+		if lastErr == nil || errors.IsNotFound(lastErr) {
+			return true, nil
+		}
+		if !IsRetryable(lastErr) {
+			return false, lastErr
+		}
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+	return lastErr
+}
+
+// Do retries an arbitrary apiserver-backed operation fn with backoff,
+// stopping as soon as fn succeeds or returns a non-retryable error. It is
+// meant for calls like daemonset.WaitToBeRunning that do not fit the
+// Create/Delete/Get shape but still fail transiently on flaky clusters.
+func Do(ctx context.Context, backoff wait.Backoff, fn func() error) error {
+	var lastErr error
+	err := wait.ExponentialBackoffWithContext(ctx, backoff, func(ctx context.Context) (bool, error) {
+		lastErr = fn()
+		if lastErr == nil {
+			return true, nil
+		}
+		if !IsRetryable(lastErr) {
+			return false, lastErr
+		}
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+	return lastErr
+}
+
+// GetWithRetry fetches the object named by key into obj, retrying transient
+// errors with backoff.
+func GetWithRetry(ctx context.Context, cli client.Client, key client.ObjectKey, obj client.Object, backoff wait.Backoff) error {
+	var lastErr error
+	err := wait.ExponentialBackoffWithContext(ctx, backoff, func(ctx context.Context) (bool, error) {
+		lastErr = cli.Get(ctx, key, obj)
+		if lastErr == nil {
+			return true, nil
+		}
+		if !IsRetryable(lastErr) {
+			return false, lastErr
+		}
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+	return lastErr
+}